@@ -0,0 +1,390 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// These are the settings ParseGrid uses unless options say otherwise.
+const (
+	defaultFloorChar      = '.'
+	defaultImpassableChar = "#"
+	defaultMarkerChars    = "SE"
+	// Floor and marker cells cost this much by default.
+	defaultGridCost = 1
+)
+
+// GridOption configures how ParseGrid and ReadGrid interpret characters. Obtain options via the
+// functions starting with "WithGrid", e.g. WithGridCost.
+type GridOption func(*gridOptions)
+
+// Type gridOptions collects the settings of all options passed to ReadGrid.
+type gridOptions struct {
+	costs      map[rune]int
+	impassable string
+	markers    string
+	digits     bool
+}
+
+// WithGridCost lets cells showing the character `char` cost `cost`. It can be used several times
+// for different characters. Costs set this way take precedence over WithGridDigitCosts.
+func WithGridCost(char rune, cost int) GridOption {
+	return func(o *gridOptions) {
+		o.costs[char] = cost
+	}
+}
+
+// WithGridImpassable sets which characters mark cells that cannot be entered, replacing the
+// default "#". Such cells still get a node, but that node is not connected to any other one.
+func WithGridImpassable(chars string) GridOption {
+	return func(o *gridOptions) {
+		o.impassable = chars
+	}
+}
+
+// WithGridMarkers sets which characters mark special positions, replacing the default "SE". The
+// positions of markers are returned separately, e.g. to find the start and end of a path. Marker
+// cells cost one unless a different cost is set for the marker via WithGridCost.
+func WithGridMarkers(chars string) GridOption {
+	return func(o *gridOptions) {
+		o.markers = chars
+	}
+}
+
+// WithGridDigitCosts lets cells showing a digit cost the value of that digit, as is common for
+// puzzles that describe the cost of each cell.
+func WithGridDigitCosts() GridOption {
+	return func(o *gridOptions) {
+		o.digits = true
+	}
+}
+
+// Method cost determines the cost of a cell showing a character. It returns false if the
+// character is not known.
+func (o *gridOptions) cost(char rune) (int, bool) {
+	if cost, found := o.costs[char]; found {
+		return cost, true
+	}
+	if o.digits && char >= '0' && char <= '9' {
+		return int(char - '0'), true
+	}
+	if strings.ContainsRune(o.markers, char) {
+		return defaultGridCost, true
+	}
+	return 0, false
+}
+
+// ParseGrid works just like ReadGrid but takes the map as a string, e.g. a raw string literal.
+func ParseGrid(
+	text string, connections [][2]int, graphType string, opts ...GridOption,
+) (GraphOps, map[[2]int]*Node, map[rune][2]int, error) {
+	return ReadGrid(strings.NewReader(text), connections, graphType, opts...)
+}
+
+// ReadGrid creates a regular 2D grid from a text map such as the following one:
+//
+//	S..#
+//	.#..
+//	...E
+//
+// Each character is one cell. The x coordinate is the column and the y coordinate is the row, both
+// starting at zero at the top left. All lines have to have the same length. Empty lines at the
+// beginning and the end are ignored. The arguments `connections` and `graphType` work just like for
+// CreateRegular2DGrid and so do node IDs.
+//
+// By default, "." costs one, "#" is impassable, and "S" and "E" are markers that also cost one.
+// Use options to change that. Any other character results in an error. The payload of each node is
+// the character, as a rune, the node was created from.
+//
+// ReadGrid returns four values:
+// 1. A graph object suitable for path finding via FindPath.
+// 2. A map from node positions to node pointers, just like for CreateRegular2DGrid.
+// 3. A map from each marker character found to its position. A marker may occur only once.
+// 4. An error value in case there were problems.
+func ReadGrid(
+	reader io.Reader, connections [][2]int, graphType string, opts ...GridOption,
+) (GraphOps, map[[2]int]*Node, map[rune][2]int, error) {
+	options := gridOptions{
+		costs:      map[rune]int{defaultFloorChar: defaultGridCost},
+		impassable: defaultImpassableChar,
+		markers:    defaultMarkerChars,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	rows, err := readGridRows(reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	size := [2]int{0, len(rows)}
+	if len(rows) > 0 {
+		size[0] = len(rows[0])
+	}
+
+	graph, posToNode, err := CreateRegular2DGrid(size, connections, graphType, defaultGridCost)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	markers := map[rune][2]int{}
+	for y, row := range rows {
+		for x, char := range row {
+			pos := [2]int{x, y}
+			node := posToNode[pos]
+			node.Payload = char
+			if strings.ContainsRune(options.markers, char) {
+				if _, found := markers[char]; found {
+					return nil, nil, nil, fmt.Errorf("marker %q found more than once", char)
+				}
+				markers[char] = pos
+			}
+			if strings.ContainsRune(options.impassable, char) {
+				isolate(node)
+				continue
+			}
+			cost, known := options.cost(char)
+			if !known {
+				return nil, nil, nil, fmt.Errorf("unknown character %q at x:%d,y:%d", char, x, y)
+			}
+			if cost < 0 {
+				return nil, nil, nil, fmt.Errorf("negative cost for character %q", char)
+			}
+			node.Cost = cost
+		}
+	}
+	return graph, posToNode, markers, nil
+}
+
+// Function readGridRows reads all lines of a text map as runes. It drops empty lines at the
+// beginning and the end and makes sure all other lines have the same length.
+func readGridRows(reader io.Reader) ([][]rune, error) {
+	rows := [][]rune{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		row := []rune(strings.TrimRight(scanner.Text(), "\r"))
+		if len(row) == 0 && len(rows) == 0 {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read grid: %s", err.Error())
+	}
+	for len(rows) > 0 && len(rows[len(rows)-1]) == 0 {
+		rows = rows[:len(rows)-1]
+	}
+	for y, row := range rows {
+		if len(row) != len(rows[0]) {
+			return nil, fmt.Errorf(
+				"line %d has length %d but the first one has length %d", y, len(row), len(rows[0]),
+			)
+		}
+	}
+	return rows, nil
+}
+
+// Function isolate removes all connections from and to a node in a grid. Since grid connections
+// are pairwise, the nodes connected to are exactly the ones connecting to it.
+func isolate(node *Node) {
+	for len(node.connections) > 0 {
+		neigh := node.connections[0]
+		neigh.RemoveConnection(node)
+		node.RemoveConnection(neigh)
+	}
+}
+
+// GridGlyphs determines the characters RenderGrid uses for the cells of a grid. Get the default
+// glyphs via DefaultGridGlyphs.
+type GridGlyphs struct {
+	// Free is used for cells that are not covered by any other glyph.
+	Free rune
+	// Wall is used for nodes without any connections, e.g. impassable cells created by ParseGrid.
+	Wall rune
+	// Path is used for nodes on the path apart from its first and last node.
+	Path rune
+	// Start and End are used for the first and last node of the path.
+	Start, End rune
+	// Explored is used for explored nodes not on the path, see WithRenderExplored.
+	Explored rune
+	// Missing is used for positions without a node within the bounds of the grid.
+	Missing rune
+	// Overflow is used instead of a cost that has more than one digit, see WithRenderCostDigits.
+	Overflow rune
+}
+
+// DefaultGridGlyphs provides the glyphs RenderGrid uses by default. Free cells are shown as ".",
+// walls as "#", the path as "o" from "S" to "E", explored nodes as "+", and missing positions as
+// spaces.
+func DefaultGridGlyphs() GridGlyphs {
+	return GridGlyphs{
+		Free:     '.',
+		Wall:     '#',
+		Path:     'o',
+		Start:    'S',
+		End:      'E',
+		Explored: '+',
+		Missing:  ' ',
+		Overflow: '*',
+	}
+}
+
+// RenderOption configures the output of RenderGrid. Obtain options via the functions starting with
+// "WithRender", e.g. WithRenderGlyphs.
+type RenderOption func(*renderOptions)
+
+// Type renderOptions collects the settings of all options passed to RenderGrid.
+type renderOptions struct {
+	glyphs     GridGlyphs
+	explored   map[*Node]bool
+	yUp        bool
+	costDigits bool
+	separator  string
+}
+
+// WithRenderGlyphs replaces the default glyphs, see DefaultGridGlyphs.
+func WithRenderGlyphs(glyphs GridGlyphs) RenderOption {
+	return func(o *renderOptions) {
+		o.glyphs = glyphs
+	}
+}
+
+// WithRenderExplored marks nodes that have been explored by a search, e.g. the expanded nodes
+// recorded via WithTrace.
+func WithRenderExplored(nodes []*Node) RenderOption {
+	return func(o *renderOptions) {
+		for _, node := range nodes {
+			o.explored[node] = true
+		}
+	}
+}
+
+// WithRenderYUp puts the row with the largest y coordinate at the top, i.e. y increases upwards as
+// in a coordinate system. By default, y increases downwards as in a text map read via ReadGrid.
+func WithRenderYUp() RenderOption {
+	return func(o *renderOptions) {
+		o.yUp = true
+	}
+}
+
+// WithRenderCostDigits shows the cost of free cells as a digit instead of the free glyph. Costs
+// that have more than one digit are shown as the overflow glyph.
+func WithRenderCostDigits() RenderOption {
+	return func(o *renderOptions) {
+		o.costDigits = true
+	}
+}
+
+// WithRenderSeparator puts `separator` between neighbouring cells in a row, e.g. a space to make
+// the output look less squashed.
+func WithRenderSeparator(separator string) RenderOption {
+	return func(o *renderOptions) {
+		o.separator = separator
+	}
+}
+
+// RenderGrid draws a 2D grid as text, e.g. to check a path in a test or a bug report. It takes a
+// map from node positions to node pointers as returned by CreateRegular2DGrid or ParseGrid and a
+// path found in that grid, which may be empty. Each row ends with a line break. By default, the
+// output uses the same layout as ReadGrid, i.e. x is the column and y is the row starting at the
+// top. All positions between the smallest and largest coordinates are drawn.
+//
+// For example, rendering the path found in the grid from the example for ReadGrid gives:
+//
+//	Soo#
+//	.#o.
+//	..oE
+func RenderGrid(posToNode map[[2]int]*Node, path []*Node, opts ...RenderOption) string {
+	options := renderOptions{glyphs: DefaultGridGlyphs(), explored: map[*Node]bool{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if len(posToNode) == 0 {
+		return ""
+	}
+	onPath := make(map[*Node]rune, len(path))
+	for _, node := range path {
+		onPath[node] = options.glyphs.Path
+	}
+	if len(path) > 0 {
+		onPath[path[0]] = options.glyphs.Start
+		onPath[path[len(path)-1]] = options.glyphs.End
+	}
+
+	minPos, maxPos := gridBounds(posToNode)
+	builder := strings.Builder{}
+	for row := minPos[1]; row <= maxPos[1]; row++ {
+		y := row
+		if options.yUp {
+			y = maxPos[1] + minPos[1] - row
+		}
+		for x := minPos[0]; x <= maxPos[0]; x++ {
+			if x > minPos[0] {
+				builder.WriteString(options.separator)
+			}
+			node, exists := posToNode[[2]int{x, y}]
+			switch glyph, found := onPath[node]; {
+			case !exists:
+				builder.WriteRune(options.glyphs.Missing)
+			case found:
+				builder.WriteRune(glyph)
+			default:
+				builder.WriteRune(options.glyph(node))
+			}
+		}
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// Method glyph determines the glyph for a node that is not on the path.
+func (o *renderOptions) glyph(node *Node) rune {
+	const maxDigit = 9
+	switch {
+	case o.explored[node]:
+		return o.glyphs.Explored
+	case len(node.connections) == 0:
+		return o.glyphs.Wall
+	case o.costDigits && node.Cost > maxDigit:
+		return o.glyphs.Overflow
+	case o.costDigits:
+		return rune('0' + node.Cost)
+	default:
+		return o.glyphs.Free
+	}
+}
+
+// Function gridBounds determines the smallest and largest coordinates in each direction.
+func gridBounds(posToNode map[[2]int]*Node) ([2]int, [2]int) {
+	first := true
+	minPos, maxPos := [2]int{}, [2]int{}
+	for pos := range posToNode {
+		for dim := range pos {
+			if first || pos[dim] < minPos[dim] {
+				minPos[dim] = pos[dim]
+			}
+			if first || pos[dim] > maxPos[dim] {
+				maxPos[dim] = pos[dim]
+			}
+		}
+		first = false
+	}
+	return minPos, maxPos
+}
@@ -0,0 +1,170 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGrid(t *testing.T) {
+	text := `
+S..#
+.#..
+...E
+`
+	for _, graphType := range []string{"default", "heaped", "bucket"} {
+		graph, posToNode, markers, err := ParseGrid(text, fourNeighbours, graphType)
+		assert.NoError(t, err)
+		assert.Equal(t, 12, graph.Len())
+		assert.Equal(t, 12, len(posToNode))
+		assert.Equal(t, map[rune][2]int{'S': [2]int{0, 0}, 'E': [2]int{3, 2}}, markers)
+
+		wall := posToNode[[2]int{3, 0}]
+		assert.Equal(t, "x:3,y:0", wall.ID)
+		assert.Equal(t, '#', wall.Payload)
+		assert.Empty(t, wall.connections)
+		assert.False(t, posToNode[[2]int{2, 0}].connectedTo(wall))
+		assert.Equal(t, 3, len(posToNode[[2]int{2, 1}].connections))
+
+		start, end := posToNode[markers['S']], posToNode[markers['E']]
+		path, err := FindPath(graph, start, end, zeroHeuristic)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, len(path))
+		for _, node := range path {
+			assert.Equal(t, 1, node.Cost)
+		}
+	}
+}
+
+func TestParseGridOptions(t *testing.T) {
+	text := "1~9\r\n2X3\r\n"
+	_, posToNode, markers, err := ParseGrid(
+		text, fourNeighbours, "heaped",
+		WithGridDigitCosts(), WithGridCost('~', 7), WithGridCost('9', 0),
+		WithGridImpassable("~"), WithGridMarkers("X"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[rune][2]int{'X': [2]int{1, 1}}, markers)
+
+	costs := map[[2]int]int{}
+	for pos, node := range posToNode {
+		costs[pos] = node.Cost
+	}
+	expected := map[[2]int]int{
+		[2]int{0, 0}: 1, [2]int{1, 0}: 1, [2]int{2, 0}: 0,
+		[2]int{0, 1}: 2, [2]int{1, 1}: 1, [2]int{2, 1}: 3,
+	}
+	assert.Equal(t, expected, costs)
+	assert.Empty(t, posToNode[[2]int{1, 0}].connections)
+
+	_, _, _, err = ParseGrid("X.X", fourNeighbours, "heaped", WithGridMarkers("X"))
+	assert.Error(t, err)
+}
+
+func TestReadGrid(t *testing.T) {
+	reader := strings.NewReader("S.\n.E")
+	graph, posToNode, markers, err := ReadGrid(reader, fourNeighbours, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, graph.Len())
+	assert.Equal(t, 4, len(posToNode))
+	assert.Equal(t, 2, len(markers))
+
+	graph, posToNode, markers, err = ReadGrid(strings.NewReader(""), fourNeighbours, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, graph.Len())
+	assert.Empty(t, posToNode)
+	assert.Empty(t, markers)
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, fmt.Errorf("some error")
+}
+
+func TestReadGridFailure(t *testing.T) {
+	for _, text := range []string{"..\n.", "..\n\n..", ".?", "SS"} {
+		_, _, _, err := ParseGrid(text, fourNeighbours, "heaped")
+		assert.Error(t, err, text)
+	}
+	_, _, _, err := ParseGrid("..", fourNeighbours, "unknownType")
+	assert.Error(t, err)
+	_, _, _, err = ParseGrid("..", fourNeighbours, "heaped", WithGridCost('.', -1))
+	assert.Error(t, err)
+	_, _, _, err = ReadGrid(errReader{}, fourNeighbours, "heaped")
+	assert.Error(t, err)
+}
+
+func TestRenderGrid(t *testing.T) {
+	text := `
+S..#
+.#..
+...E
+`
+	graph, posToNode, markers, err := ParseGrid(text, fourNeighbours, "heaped")
+	assert.NoError(t, err)
+	// Markers are only known to ParseGrid. Without a path, their cells are free cells.
+	assert.Equal(t, "...#\n.#..\n....\n", RenderGrid(posToNode, nil))
+
+	trace := Trace{}
+	start, end := posToNode[markers['S']], posToNode[markers['E']]
+	path, err := FindPath(graph, start, end, zeroHeuristic, WithTrace(&trace))
+	assert.NoError(t, err)
+	expected := `
+S o o #
++ # o +
++ + o E
+`
+	assert.Equal(
+		t, expected[1:],
+		RenderGrid(posToNode, path, WithRenderExplored(trace.Expanded), WithRenderSeparator(" ")),
+	)
+
+	expected = `
+..oE
+.#o.
+Soo#
+`
+	assert.Equal(t, expected[1:], RenderGrid(posToNode, path, WithRenderYUp()))
+}
+
+func TestRenderGridOptions(t *testing.T) {
+	_, posToNode, err := CreateRegular2DGrid([2]int{3, 2}, fourNeighbours, "default", 0)
+	assert.NoError(t, err)
+	posToNode[[2]int{1, 0}].Cost = 12
+	posToNode[[2]int{2, 1}].Cost = 3
+	delete(posToNode, [2]int{0, 1})
+	// Shifting the grid does not matter.
+	shifted := map[[2]int]*Node{}
+	for pos, node := range posToNode {
+		shifted[[2]int{pos[0] - 5, pos[1] + 2}] = node
+	}
+
+	path := []*Node{posToNode[[2]int{0, 0}], posToNode[[2]int{1, 0}]}
+	glyphs := DefaultGridGlyphs()
+	glyphs.Start, glyphs.End, glyphs.Missing, glyphs.Overflow = 'A', 'B', '_', '!'
+	assert.Equal(t, "AB0\n_03\n", RenderGrid(
+		shifted, path, WithRenderGlyphs(glyphs), WithRenderCostDigits(),
+	))
+	assert.Equal(t, "0*0\n 03\n", RenderGrid(shifted, nil, WithRenderCostDigits()))
+	assert.Equal(t, "", RenderGrid(map[[2]int]*Node{}, path))
+}
@@ -27,9 +27,12 @@ import (
 var (
 	extractPath     = ExtractPath
 	findReversePath = FindReversePath
-	resetFnGetter   = getNodeResetFn
 )
 
+// This error is returned, wrapped, by FindPath if the end node cannot be reached. It allows telling
+// unreachable nodes apart from other problems.
+var errNoPath = fmt.Errorf("no connection to end node found from start node")
+
 // Error is the error type that can be returned by the astar package. It is used to determine
 // which errors occurred inside the package and which ones occurred outside of it.
 type Error struct {
@@ -40,15 +43,6 @@ func (e Error) Error() string {
 	return e.message
 }
 
-func getNodeResetFn(resetGraph GraphOps) func(*Node) error {
-	return func(node *Node) error {
-		node.prev = nil
-		node.trackedCost = defaultCost
-		node.graph = resetGraph
-		return nil
-	}
-}
-
 func getPanicHandler(err *error) func() {
 	return func() {
 		if recovered := recover(); recovered != nil {
@@ -72,20 +66,27 @@ func getPanicHandler(err *error) func() {
 // is returned in the correct order. This is achieved by using the normal algorithm and reversing
 // the path at the end.
 //
-// This function requires you to provide one of the graph types from this package as the `graph`
-// argument. Use NewGraph or NewHeapedGraph to obtain a suitable data structure. If you want to use
-// your own implementation of a GraphOps, you will need to use FindReversePath instead.
+// This function requires the `graph` argument to implement GraphFactory, which is used to create
+// the open and closed lists. Use NewGraph, NewHeapedGraph, or NewBucketGraph to obtain a suitable
+// data structure. Your own implementation of a GraphOps can also be used if it implements
+// GraphFactory. Such graphs will have to use the TrackedCost and UpdateIfBetter methods of Node.
 //
-// This implementation modifies the original nodes during execution! In the end, the nodes are
-// reverted to null states (private members set to the appropriate zero values), which allows you to
-// use the same input graph again with FindPath.
+// This implementation modifies the original nodes during execution! The modifications are tagged
+// with the search that made them and ignored by later searches, which allows you to use the same
+// input graph again with FindPath. Nodes are never reset explicitly. Thus, the time a search takes
+// only depends on the number of nodes it explores, not on the size of the graph.
 //
 // FindPath also takes a heuristic that estimates the cost for moving from a node to the end. In the
 // easiest case, this can be built using ConstantHeuristic. This heuristic is evaluated exactly once
 // for a node when adding that node to an internal graph.
 //
+// Options can be provided to modify a single search without changing the graph, e.g. WithFilter to
+// skip some nodes.
+//
 // This function is guaranteed to handle panics from this package and not to propagate the panic.
-func FindPath(graph GraphOps, start, end *Node, heuristic Heuristic) (path []*Node, err error) {
+func FindPath(
+	graph GraphOps, start, end *Node, heuristic Heuristic, opts ...Option,
+) (path []*Node, err error) {
 	// Handle panics internally.
 	defer getPanicHandler(&err)()
 
@@ -97,37 +98,33 @@ func FindPath(graph GraphOps, start, end *Node, heuristic Heuristic) (path []*No
 		return []*Node{}, fmt.Errorf("input sanitation: end node not in graph")
 	}
 
-	// Open and closed lists will be of the same type as the input graph. To support that, we assert
-	// the type here and initialise appropriately.
-	var open, closed, resetGraph GraphOps
-	switch graph.(type) {
-	case *Graph:
-		open = NewGraph(1)
-		closed = NewGraph(1)
-		resetGraph = nil
-	case *HeapedGraph:
-		open = NewHeapedGraph(1)
-		closed = NewHeapedGraph(1)
-		resetGraph = graph
-	default:
+	// Open and closed lists will be of the same type as the input graph. To support that, the
+	// input graph has to be able to create them.
+	factory, ok := graph.(GraphFactory)
+	if !ok {
 		err := fmt.Errorf(
-			"unknown input GraphOps type, if you provided your own, use FindReversePath directly",
+			"input GraphOps does not implement GraphFactory, implement it or use FindReversePath",
 		)
 		return []*Node{}, err
 	}
+	open := factory.NewEmpty(1)
+	closed := factory.NewEmpty(1)
 	// Variable open is our open list containing all nodes that should still be checked. At the
-	// beginning, this is only the start node.
+	// beginning, this is only the start node. It might still have state left from an earlier
+	// search, which is discarded.
 	// The closed list is empty at the beginning.
+	start.prev = nil
+	start.trackedCost = defaultCost
 	open.Push(start, graphVal)
 
-	err = findReversePath(open, closed, end, heuristic)
+	err = findReversePath(open, closed, end, heuristic, opts...)
 	if err != nil {
 		return []*Node{}, fmt.Errorf("error during path finding: %s", err.Error())
 	}
-	// The only time the prev member of the end node is set is when a path has been found.
-	if end.prev == nil {
-		err := fmt.Errorf("no path found: no connection to end node found from start node")
-		return []*Node{}, err
+	// The end node is on the closed list only if it has been reached by this search. Its prev
+	// member is set only if it is not also the start node.
+	if !closed.Has(end) || end.prev == nil {
+		return []*Node{}, fmt.Errorf("no path found: %w", errNoPath)
 	}
 	// Extract a path from end to start in the order from start to end.
 	path, err = extractPath(end, start, true)
@@ -135,14 +132,6 @@ func FindPath(graph GraphOps, start, end *Node, heuristic Heuristic) (path []*No
 		return []*Node{}, fmt.Errorf("internal error during path extraction: %s", err.Error())
 	}
 
-	// Set the prev pointer back to nil. That way, the input graph can be used again. Also set the
-	// tracked cost back to zero. Also set the graph member back to the original graph if that one
-	// was a heaped graph.
-	err = graph.Apply(resetFnGetter(resetGraph))
-	if err != nil {
-		return []*Node{}, fmt.Errorf("internal error during node reset: %s", err.Error())
-	}
-
 	return path, nil
 }
 
@@ -174,25 +163,53 @@ func ExtractPath(end, start *Node, orgOrder bool) ([]*Node, error) {
 
 // FindReversePath finds a reverse path from the start node to the end node. Follow the prev member
 // of the end node to traverse the path backwards. To use this function, in the beginning, the open
-// list must contain the start node and the closed list must be empty.
+// list must contain the start node and the closed list must be empty. State left on any node by
+// earlier searches is ignored. In particular, nodes on the open list at the beginning have neither
+// a predecessor nor any tracked cost, just like the start node.
+//
+// Options can be provided just as for FindPath.
 //
 // This function may panic. If you want panics to be handled internally, use FindPath instead.
-func FindReversePath(open, closed GraphOps, end *Node, heuristic Heuristic) error {
+func FindReversePath(open, closed GraphOps, end *Node, heuristic Heuristic, opts ...Option) error {
+	options := collectOptions(opts)
+	options.trace.start()
+	if options.tieBreaker != nil {
+		breakable, ok := open.(TieBreakable)
+		if !ok {
+			return fmt.Errorf("open list does not support tie-breaking")
+		}
+		breakable.SetTieBreaker(options.tieBreaker)
+	}
+	// All nodes reached by this search are tagged with this stamp.
+	generation := nextStamp()
+	err := open.Apply(func(node *Node) error {
+		node.reach(generation)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 	for open.Len() != 0 && !closed.Has(end) {
 		// Find the next cheapest node from the open list. This removes it as well as return it.
 		nextCheckNode := open.PopCheapest()
 		// Add this node to the closed list.
 		closed.Push(nextCheckNode, heuristic(nextCheckNode))
+		options.trace.expand(nextCheckNode)
 		// Process each of the neighbours.
-		for neigh := range nextCheckNode.connections {
+		for _, neigh := range nextCheckNode.connections {
 			// If a neighbour is already on the closed list, skip it. Don't modify it at all.
 			if closed.Has(neigh) {
 				continue
 			}
+			// Skip neighbours the user does not want to enter. They are never added to any list.
+			if !options.allows(neigh) {
+				continue
+			}
 			if open.Has(neigh) {
 				// Update the node in case we found a better path to it.
 				open.UpdateIfBetter(neigh, nextCheckNode, nextCheckNode.trackedCost)
 			} else {
+				neigh.reach(generation)
 				if neigh.prev != nil {
 					return fmt.Errorf("node %s already has a predecessor", neigh.ToString())
 				}
@@ -203,5 +220,5 @@ func FindReversePath(open, closed GraphOps, end *Node, heuristic Heuristic) erro
 			}
 		}
 	}
-	return nil
+	return options.trace.finish(open)
 }
@@ -18,6 +18,7 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package astar
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -43,6 +44,118 @@ func (mo *mockGraphOps) Apply(func(*Node) error) error {
 }
 func (mo *mockGraphOps) UpdateIfBetter(*Node, *Node, int) {}
 
+// A custom graph ops implementation based on a slice that can be used with FindPath.
+type sliceGraphOps []*Node
+
+func (so *sliceGraphOps) NewEmpty(sizeHint int) GraphOps {
+	result := make(sliceGraphOps, 0, sizeHint)
+	return &result
+}
+func (so *sliceGraphOps) Len() int {
+	return len(*so)
+}
+func (so *sliceGraphOps) Has(node *Node) bool {
+	for _, member := range *so {
+		if member == node {
+			return true
+		}
+	}
+	return false
+}
+func (so *sliceGraphOps) Add(node *Node) {
+	so.Push(node, GraphVal())
+}
+func (so *sliceGraphOps) Push(node *Node, _ int) {
+	// The estimate is ignored. That way, this is Dijkstra's algorithm.
+	if !so.Has(node) {
+		*so = append(*so, node)
+	}
+}
+func (so *sliceGraphOps) Remove(node *Node) {
+	for idx, member := range *so {
+		if member == node {
+			*so = append((*so)[:idx], (*so)[idx+1:]...)
+			return
+		}
+	}
+}
+func (so *sliceGraphOps) PopCheapest() *Node {
+	if len(*so) == 0 {
+		return nil
+	}
+	cheapest := (*so)[0]
+	for _, member := range *so {
+		if member.TrackedCost() < cheapest.TrackedCost() {
+			cheapest = member
+		}
+	}
+	so.Remove(cheapest)
+	return cheapest
+}
+func (so *sliceGraphOps) Apply(fn func(*Node) error) error {
+	for _, member := range *so {
+		if err := fn(member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (so *sliceGraphOps) UpdateIfBetter(node, prev *Node, cost int) {
+	node.UpdateIfBetter(prev, cost)
+}
+
+func TestFindPathCustomGraphFactory(t *testing.T) {
+	nodes := []*Node{}
+	graph := sliceGraphOps{}
+	for idx, cost := range []int{0, 5, 1, 1, 1} {
+		node, err := NewNode(fmt.Sprint(idx), cost, 0, nil)
+		assert.NoError(t, err)
+		graph.Add(node)
+		nodes = append(nodes, node)
+	}
+	// The direct way via node 1 is more expensive than the detour via nodes 2 and 3.
+	nodes[0].AddPairwiseConnection(nodes[1])
+	nodes[1].AddPairwiseConnection(nodes[4])
+	nodes[0].AddPairwiseConnection(nodes[2])
+	nodes[2].AddPairwiseConnection(nodes[3])
+	nodes[3].AddPairwiseConnection(nodes[4])
+
+	path, err := FindPath(&graph, nodes[0], nodes[4], zeroHeuristic)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{nodes[0], nodes[2], nodes[3], nodes[4]}, path)
+
+	// The graph can be used again. Nothing is left over from the previous search.
+	nodes[2].Cost = 10
+	path, err = FindPath(&graph, nodes[0], nodes[4], zeroHeuristic)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{nodes[0], nodes[1], nodes[4]}, path)
+}
+
+// A custom graph ops implementation that forgets which nodes it contains.
+type forgetfulGraphOps struct {
+	sliceGraphOps
+}
+
+func (fo *forgetfulGraphOps) Has(_ *Node) bool {
+	return false
+}
+
+func TestFindReversePathFailureAlreadyConnection(t *testing.T) {
+	nodeA, _ := NewNode("A", 0, 0, nil)
+	nodeB, _ := NewNode("B", 0, 0, nil)
+	nodeC, _ := NewNode("C", 0, 0, nil)
+	nodeA.AddPairwiseConnection(nodeB)
+	nodeA.AddPairwiseConnection(nodeC)
+	nodeB.AddPairwiseConnection(nodeC)
+
+	// Node C is reached a second time by the same search but the open list claims not to know it.
+	open := forgetfulGraphOps{}
+	open.Push(nodeA, 0)
+	err := FindReversePath(&open, &Graph{}, nodeC, zeroHeuristic)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "predecessor")
+}
+
 func TestFindPathFailureCustomGraphOps(t *testing.T) {
 
 	mockStart, _ := NewNode("start", 0, 0, nil)
@@ -408,3 +408,80 @@ func TestOneWayConnection(t *testing.T) {
 		nodes[7].RemoveConnection(nodes[8])
 	}
 }
+
+func TestFindPathDeterministic(t *testing.T) {
+	for _, graphType := range []string{"default", "heaped"} {
+		strs := [][]string{}
+		for run := 0; run < 5; run++ {
+			// Many paths are equally cheap on a grid with uniform costs.
+			graph, posToNode, err := CreateRegular2DGrid(
+				[2]int{10, 10}, fourNeighbours, graphType, 1,
+			)
+			assert.NoError(t, err)
+			start, end := posToNode[[2]int{0, 0}], posToNode[[2]int{9, 9}]
+			path, err := FindPath(graph, start, end, zeroHeuristic)
+			assert.NoError(t, err)
+
+			// Compare string representations since the nodes differ for each run.
+			runStrs := []string{}
+			for _, node := range path {
+				runStrs = append(runStrs, node.ToString())
+			}
+			strs = append(strs, runStrs)
+		}
+		for _, runStrs := range strs[1:] {
+			assert.Equal(t, strs[0], runStrs)
+		}
+	}
+}
+
+func TestFindPathRepeatedQueries(t *testing.T) {
+	size := 50
+	createGrid := func(graphType string) (GraphOps, map[[2]int]*Node) {
+		graph, posToNode, err := CreateRegular2DGrid(
+			[2]int{size, size}, fourNeighbours, graphType, 1,
+		)
+		assert.NoError(t, err)
+		for pos, node := range posToNode {
+			node.Cost = 1 + (pos[0]+2*pos[1])%4
+		}
+		return graph, posToNode
+	}
+	queries := [][2][2]int{
+		{{0, 0}, {size - 1, size - 1}},
+		{{size - 1, size - 1}, {0, 0}},
+		{{10, 10}, {12, 10}},
+		{{5, 40}, {30, 2}},
+		{{30, 2}, {5, 40}},
+	}
+	for _, graphType := range []string{"default", "heaped", "bucket"} {
+		graph, posToNode := createGrid(graphType)
+		for _, query := range queries {
+			// A fresh grid has no state left over from earlier searches.
+			freshGraph, freshPosToNode := createGrid(graphType)
+			freshPath, err := FindPath(
+				freshGraph, freshPosToNode[query[0]], freshPosToNode[query[1]], zeroHeuristic,
+			)
+			assert.NoError(t, err)
+
+			start, end := posToNode[query[0]], posToNode[query[1]]
+			path, err := FindPath(graph, start, end, zeroHeuristic)
+			assert.NoError(t, err)
+			assert.Equal(t, NewPath(freshPath, nil).Cost, NewPath(path, nil).Cost)
+			assert.Equal(t, size*size, graph.Len())
+			assert.True(t, graph.Has(start))
+		}
+
+		// A short query only reaches nodes close to the start.
+		start, end := posToNode[[2]int{20, 20}], posToNode[[2]int{21, 20}]
+		_, err := FindPath(graph, start, end, zeroHeuristic)
+		assert.NoError(t, err)
+		reached := 0
+		for _, node := range posToNode {
+			if node.generation == start.generation {
+				reached++
+			}
+		}
+		assert.Less(t, reached, size)
+	}
+}
@@ -32,7 +32,7 @@ var errMock = fmt.Errorf("some error")
 
 // Set up test cases for find path. The two functions used by it will return the provided error
 // values.
-func setUpFindPath(errFindReverse, errExtract, errCleanUp error, connect bool) func() {
+func setUpFindPath(errFindReverse, errExtract error, connect bool) func() {
 
 	node1, _ := NewNode("start", 0, 0, nil)
 	node2, _ := NewNode("end", 0, 0, nil)
@@ -57,16 +57,12 @@ func setUpFindPath(errFindReverse, errExtract, errCleanUp error, connect bool) f
 		return mockPath, errExtract
 	}
 
-	findReversePath = func(_, _ GraphOps, _ *Node, _ Heuristic) error {
+	// Pretend the end node has been reached.
+	findReversePath = func(_, closed GraphOps, end *Node, _ Heuristic, _ ...Option) error {
+		closed.Add(end)
 		return errFindReverse
 	}
 
-	resetFnGetter = func(_ GraphOps) func(_ *Node) error {
-		return func(_ *Node) error {
-			return errCleanUp
-		}
-	}
-
 	return func() {
 		// Revert changes.
 		extractPath = ExtractPath
@@ -80,7 +76,7 @@ func setUpFindPath(errFindReverse, errExtract, errCleanUp error, connect bool) f
 }
 
 func TestFindPathSuccess(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, true)
+	tearDown := setUpFindPath(nil, nil, true)
 	defer tearDown()
 
 	path, err := FindPath(&mockGraph, mockStart, mockEnd, mockHeuristic)
@@ -92,7 +88,7 @@ func TestFindPathSuccess(t *testing.T) {
 }
 
 func TestFindPathFailurePathExtraction(t *testing.T) {
-	tearDown := setUpFindPath(errMock, nil, nil, true)
+	tearDown := setUpFindPath(errMock, nil, true)
 	defer tearDown()
 
 	_, err := FindPath(&mockGraph, mockStart, mockEnd, mockHeuristic)
@@ -100,7 +96,7 @@ func TestFindPathFailurePathExtraction(t *testing.T) {
 }
 
 func TestFindPathFailurePathFinding(t *testing.T) {
-	tearDown := setUpFindPath(nil, errMock, nil, true)
+	tearDown := setUpFindPath(nil, errMock, true)
 	defer tearDown()
 
 	_, err := FindPath(&mockGraph, mockStart, mockEnd, mockHeuristic)
@@ -108,7 +104,7 @@ func TestFindPathFailurePathFinding(t *testing.T) {
 }
 
 func TestFindPathFailureNoEnd(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, true)
+	tearDown := setUpFindPath(nil, nil, true)
 	defer tearDown()
 
 	_, err := FindPath(&mockGraph, mockStart, nil, mockHeuristic)
@@ -116,25 +112,30 @@ func TestFindPathFailureNoEnd(t *testing.T) {
 }
 
 func TestFindPathFailureNoStart(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, true)
+	tearDown := setUpFindPath(nil, nil, true)
 	defer tearDown()
 
 	_, err := FindPath(&mockGraph, nil, mockEnd, mockHeuristic)
 	assert.Error(t, err)
 }
 
-func TestFindPathFailureAlreadyConnection(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, true)
+func TestFindPathStaleConnection(t *testing.T) {
+	tearDown := setUpFindPath(nil, nil, true)
 	defer tearDown()
 
 	findReversePath = FindReversePath
+	// The end node has a predecessor left over from somewhere else. It is ignored.
+	mockEnd.prev = mockEnd
+	mockEnd.trackedCost = 100
 
-	_, err := FindPath(&mockGraph, mockStart, mockEnd, mockHeuristic)
-	assert.Error(t, err)
+	path, err := FindPath(&mockGraph, mockStart, mockEnd, mockHeuristic)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{mockStart, mockEnd}, path)
+	assert.Zero(t, mockEnd.trackedCost)
 }
 
 func TestFindPathFailureNoConnectionToEnd(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, false)
+	tearDown := setUpFindPath(nil, nil, false)
 	defer tearDown()
 
 	mockEnd.RemoveConnection(mockStart)
@@ -147,7 +148,7 @@ func TestFindPathFailureNoConnectionToEnd(t *testing.T) {
 }
 
 func TestExtractPathSuccessNoReverse(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, true)
+	tearDown := setUpFindPath(nil, nil, true)
 	defer tearDown()
 
 	path, err := ExtractPath(mockEnd, mockStart, false)
@@ -159,7 +160,7 @@ func TestExtractPathSuccessNoReverse(t *testing.T) {
 }
 
 func TestExtractPathSuccessReverse(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, true)
+	tearDown := setUpFindPath(nil, nil, true)
 	defer tearDown()
 
 	path, err := ExtractPath(mockEnd, mockStart, true)
@@ -171,7 +172,7 @@ func TestExtractPathSuccessReverse(t *testing.T) {
 }
 
 func TestExtractPathFailureNoConnection(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, false)
+	tearDown := setUpFindPath(nil, nil, false)
 	defer tearDown()
 
 	_, err := ExtractPath(mockEnd, mockStart, true)
@@ -179,7 +180,7 @@ func TestExtractPathFailureNoConnection(t *testing.T) {
 }
 
 func TestFindPathBetterConnection(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, nil, true)
+	tearDown := setUpFindPath(nil, nil, true)
 	defer tearDown()
 
 	mockMid, _ := NewNode("mid", 0, 0, nil)
@@ -203,12 +204,19 @@ func TestFindPathBetterConnection(t *testing.T) {
 	assert.NotEqual(t, orgCost, mockMid.trackedCost)
 }
 
-func TestFindPathFailureNodeReset(t *testing.T) {
-	tearDown := setUpFindPath(nil, nil, errMock, true)
-	defer tearDown()
+func TestFindReversePathResetsOpenList(t *testing.T) {
+	_, nodeA, nodeB, nodeC := setUpChain(t)
+	// Leave state on the start node as if an earlier search had reached it via another node.
+	nodeA.prev = nodeC
+	nodeA.trackedCost = 100
 
-	_, err := FindPath(&mockGraph, mockStart, mockEnd, mockHeuristic)
-	assert.Error(t, err)
+	err := FindReversePath(&Graph{nodeA: graphVal}, &Graph{}, nodeC, zeroHeuristic)
+	assert.NoError(t, err)
+	assert.Nil(t, nodeA.prev)
+	assert.Equal(t, 5, nodeC.trackedCost)
+	path, err := ExtractPath(nodeC, nodeA, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{nodeA, nodeB, nodeC}, path)
 }
 
 func TestPanicHandlerNoPanic(t *testing.T) {
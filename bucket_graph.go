@@ -0,0 +1,243 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BucketGraph is a collection of nodes stored in buckets, one for each total cost, i.e. the sum of
+// a node's tracked cost and its estimate. Using it for path finding results in what is known as
+// Dial's algorithm. Adding a node and retrieving the cheapest one take constant time on average,
+// which makes it faster than the HeapedGraph if total costs are small integers. Memory usage grows
+// with the largest total cost, so prefer the HeapedGraph if costs can be large. Estimates must not
+// be negative. Get a bucket graph via NewBucketGraph.
+//
+// Like for the HeapedGraph, a node can only be in a single bucket graph at a time. Each node is
+// assigned to its estimate. That means a node's estimate will never be able to change once added.
+type BucketGraph struct {
+	// Member buckets holds, for each total cost, the nodes with that total cost. Buckets can also
+	// contain outdated elements for nodes whose tracked cost has been lowered after adding them.
+	buckets [][]HeapElement
+	// Member cursor is the lowest total cost whose bucket may contain nodes.
+	cursor int
+	// Member count is the number of nodes in the graph.
+	count int
+	// Member stale is the number of outdated elements in the buckets. As long as there are none,
+	// every element describes a node added to the graph. That is important when using the graph as
+	// input to FindPath since nodes are moved to other graphs during the search.
+	stale int
+	// Member stamp identifies graphs used as search lists, see NewEmpty. It is zero otherwise.
+	stamp uint64
+	// Member estimates holds the estimate each node in the graph was added with. It is needed to
+	// find the new bucket of a node whose tracked cost is lowered. Nodes are removed from it once
+	// they leave the graph.
+	estimates map[*Node]int
+}
+
+// NewBucketGraph obtains a new bucket graph. Specify the estimated number of nodes as argument to
+// boost performance. See BucketGraph for details.
+func NewBucketGraph(estimatedSize int) GraphOps {
+	// Nodes added via Add all end up in the first bucket.
+	return &BucketGraph{
+		buckets:   [][]HeapElement{make([]HeapElement, 0, estimatedSize)},
+		estimates: make(map[*Node]int, estimatedSize),
+	}
+}
+
+// NewEmpty creates an empty bucket graph meant to be used as open or closed list by FindPath. Such
+// a search list keeps track of its nodes separately. Thus, a node can be in one search list and one
+// other bucket graph at the same time. See GraphFactory.
+func (g *BucketGraph) NewEmpty(sizeHint int) GraphOps {
+	graph := NewBucketGraph(sizeHint).(*BucketGraph)
+	graph.stamp = nextStamp()
+	return graph
+}
+
+// Method current determines whether an element in a bucket still describes a node in the graph.
+// Elements become outdated when a node's tracked cost is lowered.
+func (g *BucketGraph) current(elem HeapElement, bucket int) bool {
+	return g.stale == 0 || g.Has(elem.Node) && elem.Node.trackedCost+elem.Estimate == bucket
+}
+
+// Method insert puts a node into the bucket for its total cost.
+func (g *BucketGraph) insert(node *Node, estimate int) {
+	bucket := node.trackedCost + estimate
+	if bucket < 0 {
+		panic(Error{"negative total cost not supported by bucket graph"})
+	}
+	for len(g.buckets) <= bucket {
+		g.buckets = append(g.buckets, nil)
+	}
+	g.buckets[bucket] = append(g.buckets[bucket], HeapElement{Node: node, Estimate: estimate})
+	if bucket < g.cursor {
+		g.cursor = bucket
+	}
+}
+
+// Len determines the number of elements.
+func (g *BucketGraph) Len() int {
+	return g.count
+}
+
+// Has determines whether a graph contains a specific node.
+func (g *BucketGraph) Has(node *Node) bool {
+	if g.stamp != 0 {
+		return node.list == g.stamp
+	}
+	return node.graph == g
+}
+
+// Method setMember marks a node as being in this graph or not. Nodes leaving the graph also lose
+// their estimate.
+func (g *BucketGraph) setMember(node *Node, member bool) {
+	switch {
+	case g.stamp != 0 && member:
+		node.list = g.stamp
+	case g.stamp != 0:
+		node.list = 0
+	case member:
+		node.graph = g
+	default:
+		node.graph = nil
+	}
+	if !member {
+		delete(g.estimates, node)
+	}
+}
+
+// Add adds a node to the graph. If the node already exists, this a no-op. This panics if the node
+// already has a different graph set.
+func (g *BucketGraph) Add(node *Node) {
+	if !g.Has(node) {
+		if g.stamp == 0 && node.graph != nil {
+			panic(Error{"different graph already set"})
+		}
+		g.Push(node, graphVal)
+	}
+}
+
+// Push adds a node to the graph, including its estimate. If the node already exists, this a no-op.
+func (g *BucketGraph) Push(node *Node, estimate int) {
+	if !g.Has(node) {
+		g.insert(node, estimate)
+		g.estimates[node] = estimate
+		g.setMember(node, true)
+		g.count++
+	}
+}
+
+// Remove removes a node from the graph. If the node does not exist, this a no-op. This function is
+// inefficient but not needed for the algorithm in general.
+func (g *BucketGraph) Remove(node *Node) {
+	if !g.Has(node) {
+		return
+	}
+	for bucket := g.cursor; bucket < len(g.buckets); bucket++ {
+		for idx, elem := range g.buckets[bucket] {
+			if elem.Node == node && g.current(elem, bucket) {
+				g.buckets[bucket] = append(g.buckets[bucket][:idx], g.buckets[bucket][idx+1:]...)
+				g.setMember(node, false)
+				g.count--
+				return
+			}
+		}
+	}
+}
+
+// PopCheapest retrieves one of the cheapest nodes and removes it. This will return nil if the graph
+// is empty. Among equally cheap nodes, the one added last is retrieved first.
+func (g *BucketGraph) PopCheapest() *Node {
+	for ; g.cursor < len(g.buckets); g.cursor++ {
+		bucket := g.buckets[g.cursor]
+		for len(bucket) > 0 {
+			elem := bucket[len(bucket)-1]
+			bucket = bucket[:len(bucket)-1]
+			if g.current(elem, g.cursor) {
+				g.buckets[g.cursor] = bucket
+				g.setMember(elem.Node, false)
+				g.count--
+				return elem.Node
+			}
+			g.stale--
+		}
+		g.buckets[g.cursor] = bucket
+	}
+	return nil
+}
+
+// Apply applies a function to all nodes in the graph.
+func (g *BucketGraph) Apply(fn func(*Node) error) error {
+	for bucket := g.cursor; bucket < len(g.buckets); bucket++ {
+		for _, elem := range g.buckets[bucket] {
+			if !g.current(elem, bucket) {
+				continue
+			}
+			if err := fn(elem.Node); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateIfBetter updates a node's best connection if that is cheaper than any previously found one.
+// It takes the node to update, the new possible best predecessor and the cost for reaching that
+// predecessor. The node is moved to the bucket for its new total cost in constant time.
+func (g *BucketGraph) UpdateIfBetter(node, prev *Node, newCost int) {
+	if !g.Has(node) {
+		panic(Error{"cannot update node outside this graph"})
+	}
+	// The old element becomes outdated automatically once the tracked cost changes.
+	if node.UpdateIfBetter(prev, newCost) {
+		g.insert(node, g.estimates[node])
+		g.stale++
+	}
+}
+
+// ToString provides a string representation of the graph. The nodes are sorted according to their
+// user-defined names. If you provide a heuristic != nil, the value that heuristic provides for each
+// node is also provided at the end of a line. Providing nil will use the stored estimates.
+func (g *BucketGraph) ToString(heuristic Heuristic) string {
+	elems := []HeapElement{}
+	for bucket := g.cursor; bucket < len(g.buckets); bucket++ {
+		for _, elem := range g.buckets[bucket] {
+			if g.current(elem, bucket) {
+				elems = append(elems, elem)
+			}
+		}
+	}
+	sort.SliceStable(elems, func(idx1, idx2 int) bool {
+		return elems[idx1].Node.ID < elems[idx2].Node.ID
+	})
+
+	str := ""
+	for idx, elem := range elems {
+		str += elem.Node.ToString()
+		estimate := elem.Estimate
+		if heuristic != nil {
+			estimate = heuristic(elem.Node)
+		}
+		str += fmt.Sprintf(" -> %d", estimate)
+		if idx < len(elems)-1 {
+			str += "\n"
+		}
+	}
+	return str
+}
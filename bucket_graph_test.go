@@ -0,0 +1,245 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBucketGraph(t *testing.T) {
+	graph := NewBucketGraph(100).(*BucketGraph)
+	assert.Equal(t, 1, len(graph.buckets))
+	assert.Equal(t, 100, cap(graph.buckets[0]))
+}
+
+func TestBucketGraphAddRemoveSuccess(t *testing.T) {
+	node, err := NewNode("node", 0, 0, nil)
+	assert.NoError(t, err)
+	graph := NewBucketGraph(0)
+	assert.Equal(t, 0, graph.Len())
+	graph.Add(node)
+	assert.Equal(t, 1, graph.Len())
+	assert.True(t, graph.Has(node))
+	graph.Remove(node)
+	assert.Equal(t, 0, graph.Len())
+	assert.False(t, graph.Has(node))
+	// Another removal is no problem.
+	graph.Remove(node)
+	assert.Equal(t, 0, graph.Len())
+	// Adding a node twice is no problem.
+	graph.Add(node)
+	assert.Equal(t, 1, graph.Len())
+	graph.Add(node)
+	assert.Equal(t, 1, graph.Len())
+}
+
+func TestBucketGraphPopCheapest(t *testing.T) {
+	graph := NewBucketGraph(0)
+	nodes := []*Node{}
+	for idx, estimate := range []int{3, 1, 4, 0, 2} {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), 0, 0, nil)
+		assert.NoError(t, err)
+		node.trackedCost = 1
+		graph.Push(node, estimate)
+		nodes = append(nodes, node)
+	}
+	expected := []*Node{nodes[3], nodes[1], nodes[4], nodes[0], nodes[2]}
+	for _, node := range expected {
+		assert.Equal(t, node, graph.PopCheapest())
+		assert.False(t, graph.Has(node))
+	}
+	assert.Zero(t, graph.Len())
+	assert.Nil(t, graph.PopCheapest())
+
+	// Cheaper nodes can still be added after some have been popped.
+	graph.Push(nodes[0], 0)
+	graph.Push(nodes[1], -1)
+	assert.Equal(t, nodes[1], graph.PopCheapest())
+	assert.Equal(t, nodes[0], graph.PopCheapest())
+}
+
+func TestBucketGraphPushNegativePanic(t *testing.T) {
+	node, err := NewNode("node", 0, 0, nil)
+	assert.NoError(t, err)
+	graph := NewBucketGraph(0)
+	defer func() {
+		err, wasError := recover().(Error)
+		assert.True(t, wasError)
+		assert.Error(t, err)
+	}()
+	graph.Push(node, -1)
+}
+
+func TestBucketGraphUpdateIfBetter(t *testing.T) {
+	graph := NewBucketGraph(0).(*BucketGraph)
+	prev, err := NewNode("prev", 0, 0, nil)
+	assert.NoError(t, err)
+	nodes := []*Node{}
+	for idx := 0; idx < 3; idx++ {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), 1, 0, nil)
+		assert.NoError(t, err)
+		node.trackedCost = 5
+		graph.Push(node, 2)
+		nodes = append(nodes, node)
+	}
+
+	// A more expensive connection changes nothing.
+	graph.UpdateIfBetter(nodes[1], prev, 4)
+	assert.Nil(t, nodes[1].prev)
+	assert.Zero(t, graph.stale)
+
+	graph.UpdateIfBetter(nodes[1], prev, 1)
+	assert.Equal(t, prev, nodes[1].prev)
+	assert.Equal(t, 2, nodes[1].trackedCost)
+	assert.Equal(t, 1, graph.stale)
+	// The outdated element is neither counted nor visited.
+	assert.Equal(t, 3, graph.Len())
+	visited := 0
+	assert.NoError(t, graph.Apply(func(*Node) error { visited++; return nil }))
+	assert.Equal(t, 3, visited)
+
+	assert.Equal(t, nodes[1], graph.PopCheapest())
+	assert.NotEqual(t, nodes[1], graph.PopCheapest())
+	assert.NotEqual(t, nodes[1], graph.PopCheapest())
+	assert.Nil(t, graph.PopCheapest())
+	assert.Zero(t, graph.stale)
+}
+
+func TestBucketGraphUpdateIfBetterSharedNode(t *testing.T) {
+	graph := NewBucketGraph(0).(*BucketGraph)
+	prev, err := NewNode("prev", 0, 0, nil)
+	assert.NoError(t, err)
+	node, err := NewNode("node", 1, 0, nil)
+	assert.NoError(t, err)
+	node.trackedCost = 5
+	graph.Push(node, 2)
+
+	// Other graphs may use the same node in the meantime without affecting the bucket graph.
+	heaped := NewHeapedGraph(0).(*HeapedGraph).NewEmpty(0)
+	for idx := 0; idx < 3; idx++ {
+		other, err := NewNode(fmt.Sprintf("other%d", idx), 0, 0, nil)
+		assert.NoError(t, err)
+		heaped.Push(other, idx)
+	}
+	heaped.Push(node, 7)
+	list := NewBucketGraph(0).(*BucketGraph).NewEmpty(0)
+	list.Push(node, 3)
+
+	graph.UpdateIfBetter(node, prev, 1)
+	assert.Equal(t, 2, node.trackedCost)
+	assert.Equal(t, 2, graph.estimates[node])
+	assert.Equal(t, 1, len(graph.buckets[4]))
+	assert.Equal(t, node, graph.PopCheapest())
+}
+
+func TestBucketGraphUpdateIfBetterFailure(t *testing.T) {
+	graph := NewBucketGraph(0)
+	node, err := NewNode("node", 0, 0, nil)
+	assert.NoError(t, err)
+	defer func() {
+		err, wasError := recover().(Error)
+		assert.True(t, wasError)
+		assert.Error(t, err)
+	}()
+	graph.UpdateIfBetter(node, nil, 0)
+}
+
+func TestBucketGraphApplyFail(t *testing.T) {
+	graph := NewBucketGraph(0)
+	for idx := 0; idx < 2; idx++ {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), 0, 0, nil)
+		assert.NoError(t, err)
+		graph.Add(node)
+	}
+	calls := 0
+	err := graph.Apply(func(*Node) error {
+		calls++
+		return errMock
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestBucketGraphGraphMemberSetPanic(t *testing.T) {
+	node, err := NewNode("node", 0, 0, nil)
+	assert.NoError(t, err)
+	node.graph = NewBucketGraph(0)
+	graph := NewBucketGraph(0)
+	defer func() {
+		err, wasError := recover().(Error)
+		assert.True(t, wasError)
+		assert.Error(t, err)
+	}()
+	graph.Add(node)
+}
+
+func TestBucketGraphToString(t *testing.T) {
+	graph := NewBucketGraph(0).(*BucketGraph)
+	for idx, cost := range []int{1, 2, 0, 3} {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), cost, 0, nil)
+		assert.NoError(t, err)
+		graph.Push(node, 3-idx)
+	}
+	expectedStr := "{id: node0, cost: 1, con: ['']} -> 2\n" +
+		"{id: node1, cost: 2, con: ['']} -> 3\n" +
+		"{id: node2, cost: 0, con: ['']} -> 1\n" +
+		"{id: node3, cost: 3, con: ['']} -> 4"
+	assert.Equal(t, expectedStr, graph.ToString(mockHeuristic))
+	expectedStr = "{id: node0, cost: 1, con: ['']} -> 3\n" +
+		"{id: node1, cost: 2, con: ['']} -> 2\n" +
+		"{id: node2, cost: 0, con: ['']} -> 1\n" +
+		"{id: node3, cost: 3, con: ['']} -> 0"
+	assert.Equal(t, expectedStr, graph.ToString(nil))
+}
+
+func TestFindPathBucketGraph(t *testing.T) {
+	size := 20
+	costs := func(posToNode map[[2]int]*Node) {
+		for pos, node := range posToNode {
+			node.Cost = 1 + (pos[0]*pos[1])%3
+		}
+	}
+	bucketGraph, bucketPos, err := CreateRegular2DGrid(
+		[2]int{size, size}, fourNeighbours, "bucket", 1,
+	)
+	assert.NoError(t, err)
+	costs(bucketPos)
+	heapedGraph, heapedPos, err := CreateRegular2DGrid(
+		[2]int{size, size}, fourNeighbours, "heaped", 1,
+	)
+	assert.NoError(t, err)
+	costs(heapedPos)
+
+	findPath := func(graph GraphOps, posToNode map[[2]int]*Node) []*Node {
+		end := [2]int{size - 1, size / 2}
+		heuristic, err := CreateConnectionsHeuristic2D(posToNode, end, fourNeighbours, 0)
+		assert.NoError(t, err)
+		path, err := FindPath(graph, posToNode[[2]int{0, 0}], posToNode[end], heuristic)
+		assert.NoError(t, err)
+		return path
+	}
+	expectedCost := NewPath(findPath(heapedGraph, heapedPos), nil).Cost
+	// The input graph can be used again.
+	for idx := 0; idx < 2; idx++ {
+		assert.Equal(t, expectedCost, NewPath(findPath(bucketGraph, bucketPos), nil).Cost)
+		assert.Equal(t, size*size, bucketGraph.Len())
+	}
+}
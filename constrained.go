@@ -0,0 +1,218 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+
+	goheap "container/heap"
+)
+
+// ResourceConstraint describes a secondary resource that is accumulated along a path and that must
+// not exceed a limit, e.g. battery use or a toll budget. It is used with FindConstrainedPath.
+type ResourceConstraint struct {
+	// Usage returns the amount of the resource consumed when moving from one node to a connected
+	// one. If the resource is consumed per node, simply ignore the first argument. Usage must
+	// never be negative.
+	Usage func(from, to *Node) int
+	// Limit is the maximum amount of the resource that may be consumed along the path.
+	Limit int
+	// Estimate optionally estimates the amount of the resource still needed to reach the end
+	// from a node. It is used to prune the search early and must never over-estimate. Leave it
+	// nil to disable pruning based on an estimate.
+	Estimate func(*Node) int
+}
+
+// A label is one way of reaching a node, tracking both the primary cost and the resource used. In
+// contrast to FindPath, a node can be reached in several non-dominated ways at the same time.
+type label struct {
+	node     *Node
+	cost     int
+	resource int
+	estimate int
+	prev     *label
+	// Member dominated is set once a better label for the same node has been found. Such labels
+	// are skipped when they are popped.
+	dominated bool
+}
+
+// Method dominates reports whether a label dominates or equals another one, i.e. it is at most as
+// expensive and uses at most as much of the resource.
+func (l *label) dominates(other *label) bool {
+	return l.cost <= other.cost && l.resource <= other.resource
+}
+
+type labelHeap []*label
+
+func (h *labelHeap) Len() int {
+	return len(*h)
+}
+
+func (h *labelHeap) Less(i, j int) bool {
+	iLabel := (*h)[i]
+	jLabel := (*h)[j]
+	iTotal := iLabel.cost + iLabel.estimate
+	jTotal := jLabel.cost + jLabel.estimate
+	if iTotal == jTotal {
+		// Prefer labels using less of the resource, they are more likely to lead to the end.
+		return iLabel.resource < jLabel.resource
+	}
+	return iTotal < jTotal
+}
+
+func (h *labelHeap) Swap(i, j int) {
+	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+}
+
+func (h *labelHeap) Push(x interface{}) {
+	*h = append(*h, x.(*label))
+}
+
+func (h *labelHeap) Pop() interface{} {
+	length := len(*h)
+	last := (*h)[length-1]
+	(*h)[length-1] = nil
+	*h = (*h)[0 : length-1]
+	return last
+}
+
+// FindConstrainedPath finds the path between the start and end node that has the lowest cost while
+// keeping the resource described by `constraint` within its limit. Costs are treated exactly as by
+// FindPath and the heuristic has the same meaning. It only estimates the primary cost. The path is
+// returned in the correct order, starting with the start node.
+//
+// In contrast to FindPath, a node may be reached in several ways that cannot be compared with each
+// other, e.g. a cheap way using a lot of the resource and an expensive way using little of it. All
+// such ways are tracked and those that are worse in both respects are pruned. Thus, this function
+// can take considerably longer than FindPath, in particular for generous limits on graphs with
+// many different ways of reaching a node.
+//
+// This function does not modify the nodes, which means several searches may be run on the same
+// graph concurrently. It is guaranteed to handle panics from this package and not to propagate the
+// panic.
+//
+//nolint:funlen
+func FindConstrainedPath(
+	graph GraphOps, start, end *Node, heuristic Heuristic, constraint ResourceConstraint,
+) (path []*Node, err error) {
+	// Handle panics internally.
+	defer getPanicHandler(&err)()
+
+	// Sanity checks
+	if !graph.Has(start) {
+		return []*Node{}, fmt.Errorf("input sanitation: start node not in graph")
+	}
+	if !graph.Has(end) {
+		return []*Node{}, fmt.Errorf("input sanitation: end node not in graph")
+	}
+	if constraint.Usage == nil {
+		return []*Node{}, fmt.Errorf("input sanitation: no resource usage function provided")
+	}
+	if constraint.Limit < 0 {
+		return []*Node{}, fmt.Errorf("input sanitation: negative resource limit")
+	}
+
+	estimateResource := func(node *Node) int {
+		if constraint.Estimate == nil {
+			return 0
+		}
+		return constraint.Estimate(node)
+	}
+
+	// All non-dominated labels found so far for each node.
+	labels := map[*Node][]*label{}
+	open := labelHeap{}
+
+	startLabel := &label{node: start, estimate: heuristic(start)}
+	labels[start] = []*label{startLabel}
+	goheap.Push(&open, startLabel)
+
+	for open.Len() != 0 {
+		current := goheap.Pop(&open).(*label)
+		if current.dominated {
+			continue
+		}
+		// The cheapest label at the end node is the cheapest feasible path as long as the
+		// heuristic does not over-estimate.
+		if current.node == end {
+			return labelPath(current), nil
+		}
+		for _, neigh := range current.node.connections {
+			usage := constraint.Usage(current.node, neigh)
+			if usage < 0 {
+				return []*Node{}, fmt.Errorf(
+					"negative resource usage from %s to %s", current.node.ID, neigh.ID,
+				)
+			}
+			next := &label{
+				node:     neigh,
+				cost:     current.cost + neigh.Cost,
+				resource: current.resource + usage,
+				prev:     current,
+			}
+			// Prune if the limit is already exceeded or will definitely be exceeded.
+			if next.resource+estimateResource(neigh) > constraint.Limit {
+				continue
+			}
+			if !addLabel(labels, next) {
+				continue
+			}
+			next.estimate = heuristic(neigh)
+			goheap.Push(&open, next)
+		}
+	}
+
+	err = fmt.Errorf("no path found: no connection to end node within the resource limit")
+	return []*Node{}, err
+}
+
+// Function addLabel adds a new label to the labels known for its node unless an existing label
+// dominates it. Existing labels dominated by the new one are marked as such and dropped. It returns
+// whether the label was added.
+func addLabel(labels map[*Node][]*label, newLabel *label) bool {
+	existing := labels[newLabel.node]
+	for _, known := range existing {
+		if known.dominates(newLabel) {
+			return false
+		}
+	}
+	kept := existing[:0]
+	for _, known := range existing {
+		if newLabel.dominates(known) {
+			known.dominated = true
+		} else {
+			kept = append(kept, known)
+		}
+	}
+	labels[newLabel.node] = append(kept, newLabel)
+	return true
+}
+
+// Function labelPath follows the chain of labels back to the start and returns the nodes in the
+// order from start to end.
+func labelPath(end *label) []*Node {
+	invPath := []*Node{}
+	for curr := end; curr != nil; curr = curr.prev {
+		invPath = append(invPath, curr.node)
+	}
+	path := make([]*Node, 0, len(invPath))
+	for idx := len(invPath) - 1; idx >= 0; idx-- {
+		path = append(path, invPath[idx])
+	}
+	return path
+}
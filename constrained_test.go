@@ -0,0 +1,170 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Set up a small graph with two ways from start to end. The upper one is cheap but uses a lot of
+// the resource, the lower one is expensive but uses little of it. The resource usage is stored in
+// the payload of each node:
+//
+//	#--#--#
+//	|     |
+//	S     E
+//	|     |
+//	#-----#
+func setUpConstrained(t *testing.T) (GraphOps, *Node, *Node, []*Node, []*Node) {
+	newNode := func(id string, cost, usage int) *Node {
+		node, err := NewNode(id, cost, 0, usage)
+		assert.NoError(t, err)
+		return node
+	}
+	start := newNode("start", 0, 0)
+	end := newNode("end", 1, 0)
+	upper := []*Node{newNode("u1", 1, 5), newNode("u2", 1, 5), newNode("u3", 1, 5)}
+	lower := []*Node{newNode("l1", 5, 1), newNode("l2", 5, 1)}
+
+	upperPath := append(append([]*Node{start}, upper...), end)
+	lowerPath := append(append([]*Node{start}, lower...), end)
+	for _, path := range [][]*Node{upperPath, lowerPath} {
+		for idx := 1; idx < len(path); idx++ {
+			path[idx-1].AddPairwiseConnection(path[idx])
+		}
+	}
+
+	graph := NewGraph(0)
+	for _, path := range [][]*Node{upperPath, lowerPath} {
+		for _, node := range path {
+			graph.Add(node)
+		}
+	}
+	return graph, start, end, upperPath, lowerPath
+}
+
+func payloadUsage(_, to *Node) int {
+	return to.Payload.(int)
+}
+
+func TestFindConstrainedPathGenerousLimit(t *testing.T) {
+	graph, start, end, upperPath, _ := setUpConstrained(t)
+
+	constraint := ResourceConstraint{Usage: payloadUsage, Limit: 100}
+	path, err := FindConstrainedPath(graph, start, end, mockHeuristic, constraint)
+
+	assert.NoError(t, err)
+	assert.Equal(t, upperPath, path)
+}
+
+func TestFindConstrainedPathTightLimit(t *testing.T) {
+	graph, start, end, _, lowerPath := setUpConstrained(t)
+
+	constraint := ResourceConstraint{Usage: payloadUsage, Limit: 10}
+	path, err := FindConstrainedPath(graph, start, end, mockHeuristic, constraint)
+
+	assert.NoError(t, err)
+	assert.Equal(t, lowerPath, path)
+}
+
+func TestFindConstrainedPathEstimatePrunes(t *testing.T) {
+	graph, start, end, _, lowerPath := setUpConstrained(t)
+
+	visited := map[*Node]bool{}
+	constraint := ResourceConstraint{
+		Usage: func(from, to *Node) int {
+			visited[to] = true
+			return payloadUsage(from, to)
+		},
+		Limit: 10,
+		// From the first upper node, the two remaining upper nodes still have to be traversed.
+		Estimate: func(node *Node) int {
+			if node.ID == "u1" {
+				return 10
+			}
+			return 0
+		},
+	}
+	path, err := FindConstrainedPath(graph, start, end, mockHeuristic, constraint)
+
+	assert.NoError(t, err)
+	assert.Equal(t, lowerPath, path)
+	// The upper way has been pruned right at its first node.
+	assert.False(t, visited[nodeByID(t, graph, "u2")])
+}
+
+func nodeByID(t *testing.T, graph GraphOps, id string) *Node {
+	var found *Node
+	err := graph.Apply(func(node *Node) error {
+		if node.ID == id {
+			found = node
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	return found
+}
+
+func TestFindConstrainedPathNoPath(t *testing.T) {
+	graph, start, end, _, _ := setUpConstrained(t)
+
+	constraint := ResourceConstraint{Usage: payloadUsage, Limit: 1}
+	_, err := FindConstrainedPath(graph, start, end, mockHeuristic, constraint)
+
+	assert.Error(t, err)
+}
+
+func TestFindConstrainedPathFailures(t *testing.T) {
+	graph, start, end, _, _ := setUpConstrained(t)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+
+	valid := ResourceConstraint{Usage: payloadUsage, Limit: 10}
+
+	_, err = FindConstrainedPath(graph, outside, end, mockHeuristic, valid)
+	assert.Error(t, err)
+	_, err = FindConstrainedPath(graph, start, outside, mockHeuristic, valid)
+	assert.Error(t, err)
+	_, err = FindConstrainedPath(graph, start, end, mockHeuristic, ResourceConstraint{Limit: 10})
+	assert.Error(t, err)
+	_, err = FindConstrainedPath(
+		graph, start, end, mockHeuristic, ResourceConstraint{Usage: payloadUsage, Limit: -1},
+	)
+	assert.Error(t, err)
+
+	negative := ResourceConstraint{Usage: func(_, _ *Node) int { return -1 }, Limit: 10}
+	_, err = FindConstrainedPath(graph, start, end, mockHeuristic, negative)
+	assert.Error(t, err)
+}
+
+func TestFindConstrainedPathDoesNotModifyNodes(t *testing.T) {
+	graph, start, end, _, _ := setUpConstrained(t)
+
+	constraint := ResourceConstraint{Usage: payloadUsage, Limit: 10}
+	_, err := FindConstrainedPath(graph, start, end, mockHeuristic, constraint)
+	assert.NoError(t, err)
+
+	err = graph.Apply(func(node *Node) error {
+		assert.Nil(t, node.prev)
+		assert.Zero(t, node.trackedCost)
+		return nil
+	})
+	assert.NoError(t, err)
+}
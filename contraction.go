@@ -0,0 +1,563 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	goheap "container/heap"
+)
+
+// Witness searches during contraction stop after settling this many nodes. A lower value speeds
+// up preprocessing but adds shortcuts that are not strictly needed.
+const chWitnessSettleLimit = 100
+
+// A chEdge is a connection in a contraction hierarchy. For shortcuts, middle is the index of the
+// contracted node the shortcut bypasses. For original connections, it is negative.
+type chEdge struct {
+	to     int
+	weight int
+	middle int
+}
+
+// ContractionHierarchy answers shortest-path queries on a static graph much faster than FindPath.
+// During preprocessing, nodes are contracted one after the other in order of their importance.
+// Whenever contracting a node would destroy a cheapest path between its neighbours, a shortcut is
+// added. A query then only needs to search upwards in the hierarchy from both ends, which visits
+// only a tiny part of the graph. Obtain one via NewContractionHierarchy.
+//
+// The hierarchy is only valid as long as neither costs nor connections change. It does not modify
+// any nodes, neither during preprocessing nor during queries. Thus, several queries can be run
+// concurrently. Each query reuses the state of an earlier one that has finished.
+type ContractionHierarchy struct {
+	nodes []*Node
+	index map[*Node]int
+	// Member queries holds the state of finished queries, see chQuery.
+	queries sync.Pool
+	// Member up holds, for each node, all connections to more important nodes. Member down holds,
+	// for each node, all connections from more important nodes, stored reversed, i.e. `to` is the
+	// more important node the connection originates from.
+	up   [][]chEdge
+	down [][]chEdge
+}
+
+// Type chBuilder holds the state needed while contracting nodes.
+type chBuilder struct {
+	// Members out and in hold the remaining connections between nodes that have not yet been
+	// contracted, including shortcuts. Just like for the hierarchy, connections in `in` are stored
+	// reversed. Member contracted tells which nodes have already been contracted.
+	out        [][]chEdge
+	in         [][]chEdge
+	contracted []bool
+	// Member deleted counts how many neighbours of each node have already been contracted. This
+	// helps spread contraction evenly across the graph.
+	deleted []int
+	// Members witnessDist, witnessStamp, witnessOpen, and stamp are reused by all witness
+	// searches, see witnessSearch.
+	witnessDist  []int
+	witnessStamp []int
+	witnessOpen  chPriorityHeap
+	stamp        int
+}
+
+type chShortcut struct {
+	from   int
+	to     int
+	weight int
+}
+
+// NewContractionHierarchy preprocesses a graph for fast repeated queries. Connections to nodes
+// outside the graph are ignored. The cost of following a connection is the cost of the node it
+// leads to, just like for FindPath. Preprocessing can take a while for large graphs, but it needs
+// to be done only once.
+func NewContractionHierarchy(graph GraphOps) (*ContractionHierarchy, error) {
+	nodes := make([]*Node, 0, graph.Len())
+	err := graph.Apply(func(node *Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Sort to make preprocessing reproducible.
+	sortNodesByID(nodes)
+
+	ch := &ContractionHierarchy{
+		nodes: nodes,
+		index: make(map[*Node]int, len(nodes)),
+		up:    make([][]chEdge, len(nodes)),
+		down:  make([][]chEdge, len(nodes)),
+	}
+	for idx, node := range nodes {
+		ch.index[node] = idx
+	}
+	ch.queries.New = func() interface{} {
+		return &chQuery{forward: newCHSearch(ch.up), backward: newCHSearch(ch.down)}
+	}
+
+	builder := &chBuilder{
+		out:          make([][]chEdge, len(nodes)),
+		in:           make([][]chEdge, len(nodes)),
+		contracted:   make([]bool, len(nodes)),
+		deleted:      make([]int, len(nodes)),
+		witnessDist:  make([]int, len(nodes)),
+		witnessStamp: make([]int, len(nodes)),
+	}
+	for idx, node := range nodes {
+		for _, neigh := range node.connections {
+			neighIdx, inGraph := ch.index[neigh]
+			if !inGraph || neighIdx == idx {
+				continue
+			}
+			builder.addEdge(idx, neighIdx, neigh.Cost, -1)
+		}
+	}
+
+	builder.contractAll(ch)
+	return ch, nil
+}
+
+type chPriority struct {
+	node     int
+	priority int
+}
+
+type chPriorityHeap []chPriority
+
+func (h *chPriorityHeap) Len() int {
+	return len(*h)
+}
+
+func (h *chPriorityHeap) Less(i, j int) bool {
+	if (*h)[i].priority == (*h)[j].priority {
+		return (*h)[i].node < (*h)[j].node
+	}
+	return (*h)[i].priority < (*h)[j].priority
+}
+
+func (h *chPriorityHeap) Swap(i, j int) {
+	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+}
+
+func (h *chPriorityHeap) Push(x interface{}) {
+	*h = append(*h, x.(chPriority))
+}
+
+func (h *chPriorityHeap) Pop() interface{} {
+	length := len(*h)
+	last := (*h)[length-1]
+	*h = (*h)[0 : length-1]
+	return last
+}
+
+// Method push adds an element like goheap.Push but avoids converting it to an interface, which
+// would allocate memory.
+func (h *chPriorityHeap) push(elem chPriority) {
+	*h = append(*h, elem)
+	goheap.Fix(h, len(*h)-1)
+}
+
+// Method pop removes the smallest element like goheap.Pop but avoids converting it to an
+// interface, which would allocate memory.
+func (h *chPriorityHeap) pop() chPriority {
+	elem := (*h)[0]
+	last := len(*h) - 1
+	(*h)[0] = (*h)[last]
+	*h = (*h)[:last]
+	if last > 0 {
+		goheap.Fix(h, 0)
+	}
+	return elem
+}
+
+// Method priority determines how important a node is, given the number of shortcuts contracting it
+// would add. Less important nodes are contracted first. Nodes whose contraction adds few shortcuts
+// but removes many connections are unimportant.
+func (b *chBuilder) priority(node, shortcuts int) int {
+	return shortcuts - len(b.in[node]) - len(b.out[node]) + b.deleted[node]
+}
+
+// Method contractAll contracts all nodes in order of their priorities and fills in the connections
+// of the hierarchy. The number of shortcuts needed to contract each node is cached. Contracting a
+// node mostly affects its neighbours, so only their counts become stale. Stale counts are
+// recomputed lazily once a node would be contracted next.
+func (b *chBuilder) contractAll(ch *ContractionHierarchy) {
+	numShortcuts := make([]int, len(b.out))
+	stale := make([]bool, len(b.out))
+	queue := make(chPriorityHeap, 0, len(b.out))
+	for node := range b.out {
+		numShortcuts[node] = len(b.shortcuts(node))
+		queue = append(
+			queue, chPriority{node: node, priority: b.priority(node, numShortcuts[node])},
+		)
+	}
+	goheap.Init(&queue)
+
+	for queue.Len() != 0 {
+		current := queue.pop()
+		node := current.node
+		// Skip nodes that have already been contracted and entries with outdated priorities.
+		if b.contracted[node] || current.priority != b.priority(node, numShortcuts[node]) {
+			continue
+		}
+		if stale[node] {
+			numShortcuts[node] = len(b.shortcuts(node))
+			stale[node] = false
+			queue.push(chPriority{node: node, priority: b.priority(node, numShortcuts[node])})
+			continue
+		}
+		neighbours := b.neighbours(node)
+		b.contract(node, ch)
+		for _, neigh := range neighbours {
+			stale[neigh] = true
+			queue.push(
+				chPriority{node: neigh, priority: b.priority(neigh, numShortcuts[neigh])},
+			)
+		}
+	}
+
+	// Sort connections to make queries reproducible.
+	for idx := range ch.up {
+		sortCHEdges(ch.up[idx])
+		sortCHEdges(ch.down[idx])
+	}
+}
+
+// Method neighbours determines all nodes a node is connected to in the remaining graph, in either
+// direction.
+func (b *chBuilder) neighbours(node int) []int {
+	result := make([]int, 0, len(b.out[node])+len(b.in[node]))
+	for _, edge := range b.out[node] {
+		result = append(result, edge.to)
+	}
+	for _, inEdge := range b.in[node] {
+		both := false
+		for _, outEdge := range b.out[node] {
+			both = both || outEdge.to == inEdge.to
+		}
+		if !both {
+			result = append(result, inEdge.to)
+		}
+	}
+	return result
+}
+
+func sortCHEdges(edges []chEdge) {
+	sort.Slice(edges, func(idx1, idx2 int) bool {
+		return edges[idx1].to < edges[idx2].to
+	})
+}
+
+// Method addEdge adds a connection to the remaining graph. It replaces an existing connection
+// between the same nodes. Use a negative value for middle for original connections.
+func (b *chBuilder) addEdge(from, to, weight, middle int) {
+	b.out[from] = setCHEdge(b.out[from], chEdge{to: to, weight: weight, middle: middle})
+	b.in[to] = setCHEdge(b.in[to], chEdge{to: from, weight: weight, middle: middle})
+}
+
+// Function setCHEdge adds a connection to a list or replaces the one leading to the same node.
+func setCHEdge(edges []chEdge, edge chEdge) []chEdge {
+	for idx := range edges {
+		if edges[idx].to == edge.to {
+			edges[idx] = edge
+			return edges
+		}
+	}
+	return append(edges, edge)
+}
+
+// Function removeCHEdge removes the connection leading to a node from a list, if there is one.
+func removeCHEdge(edges []chEdge, to int) []chEdge {
+	for idx := range edges {
+		if edges[idx].to == to {
+			last := len(edges) - 1
+			edges[idx] = edges[last]
+			return edges[:last]
+		}
+	}
+	return edges
+}
+
+// Method contract removes a node from the remaining graph. Its remaining connections all lead to or
+// come from more important nodes and are kept in the hierarchy. Shortcuts are added where needed.
+func (b *chBuilder) contract(node int, ch *ContractionHierarchy) {
+	for _, shortcut := range b.shortcuts(node) {
+		b.addEdge(shortcut.from, shortcut.to, shortcut.weight, node)
+	}
+	for _, edge := range b.out[node] {
+		b.in[edge.to] = removeCHEdge(b.in[edge.to], node)
+		b.deleted[edge.to]++
+	}
+	for _, edge := range b.in[node] {
+		b.out[edge.to] = removeCHEdge(b.out[edge.to], node)
+		b.deleted[edge.to]++
+	}
+	ch.up[node], ch.down[node] = b.out[node], b.in[node]
+	b.out[node], b.in[node] = nil, nil
+	b.contracted[node] = true
+}
+
+// Method shortcuts determines the shortcuts needed when contracting a node. A shortcut from a
+// predecessor to a successor is needed unless a witness search finds a way between them that avoids
+// the node and is not more expensive.
+func (b *chBuilder) shortcuts(node int) []chShortcut {
+	result := []chShortcut{}
+	for _, inEdge := range b.in[node] {
+		from := inEdge.to
+		maxWeight := 0
+		for _, outEdge := range b.out[node] {
+			if outEdge.to != from && inEdge.weight+outEdge.weight > maxWeight {
+				maxWeight = inEdge.weight + outEdge.weight
+			}
+		}
+		b.witnessSearch(from, node, maxWeight)
+		for _, outEdge := range b.out[node] {
+			if outEdge.to == from {
+				continue
+			}
+			weight := inEdge.weight + outEdge.weight
+			if b.witnessFound(outEdge.to) && b.witnessDist[outEdge.to] <= weight {
+				continue
+			}
+			result = append(result, chShortcut{from: from, to: outEdge.to, weight: weight})
+		}
+	}
+	return result
+}
+
+// Method witnessSearch determines the costs from a node to others within the remaining graph
+// without using the node that is to be contracted. The search is limited in both cost and size. The
+// costs are stored in witnessDist and valid for all nodes whose witnessStamp equals the stamp of
+// the search.
+func (b *chBuilder) witnessSearch(source, avoid, maxWeight int) {
+	b.stamp++
+	b.witnessDist[source] = 0
+	b.witnessStamp[source] = b.stamp
+	b.witnessOpen = append(b.witnessOpen[:0], chPriority{node: source, priority: 0})
+	for settled := 0; b.witnessOpen.Len() != 0 && settled < chWitnessSettleLimit; {
+		current := b.witnessOpen.pop()
+		// Nodes can be on the open list several times. Only the cheapest entry counts.
+		if current.priority > b.witnessDist[current.node] {
+			continue
+		}
+		settled++
+		if current.priority > maxWeight {
+			break
+		}
+		for _, edge := range b.out[current.node] {
+			if edge.to == avoid {
+				continue
+			}
+			newDist := current.priority + edge.weight
+			if b.witnessFound(edge.to) && b.witnessDist[edge.to] <= newDist {
+				continue
+			}
+			b.witnessDist[edge.to] = newDist
+			b.witnessStamp[edge.to] = b.stamp
+			b.witnessOpen.push(chPriority{node: edge.to, priority: newDist})
+		}
+	}
+}
+
+// Method witnessFound determines whether the last witness search reached a node.
+func (b *chBuilder) witnessFound(node int) bool {
+	return b.witnessStamp[node] == b.stamp
+}
+
+// Type chSearch is one direction of a bidirectional query. Just like for CSRSearcher, its arrays
+// have one entry per node and are reused by all queries. Entries are tagged with a stamp unique to
+// the query instead of being cleared.
+type chSearch struct {
+	edges [][]chEdge
+	// Member stamp identifies the current query. It is always odd. A node has been reached if its
+	// state is equal to the stamp. It has been settled if its state is one larger.
+	stamp uint32
+	state []uint32
+	dist  []int
+	prev  []chEdge
+	open  chPriorityHeap
+}
+
+func newCHSearch(edges [][]chEdge) *chSearch {
+	return &chSearch{
+		edges: edges,
+		stamp: 1,
+		state: make([]uint32, len(edges)),
+		dist:  make([]int, len(edges)),
+		prev:  make([]chEdge, len(edges)),
+	}
+}
+
+// Method start prepares a new query from the source node. If all stamps have been used up, the
+// state is cleared so that stamps can be reused.
+func (s *chSearch) start(source int) {
+	if s.stamp >= math.MaxUint32-csrStampsPerSearch {
+		for idx := range s.state {
+			s.state[idx] = 0
+		}
+		s.stamp = 1
+	} else {
+		s.stamp += csrStampsPerSearch
+	}
+	s.state[source] = s.stamp
+	s.dist[source] = 0
+	s.open = append(s.open[:0], chPriority{node: source, priority: 0})
+}
+
+// Method reached determines whether the current query has reached a node.
+func (s *chSearch) reached(node int) bool {
+	return s.state[node] == s.stamp || s.settled(node)
+}
+
+// Method settled determines whether the current query has settled a node.
+func (s *chSearch) settled(node int) bool {
+	return s.state[node] == s.stamp+1
+}
+
+// Method minKey determines the lowest cost on the open list, or -1 if it is empty.
+func (s *chSearch) minKey() int {
+	for s.open.Len() != 0 && s.settled(s.open[0].node) {
+		s.open.pop()
+	}
+	if s.open.Len() == 0 {
+		return -1
+	}
+	return s.open[0].priority
+}
+
+// Method step settles the next node and returns it.
+func (s *chSearch) step() int {
+	current := s.open.pop()
+	s.state[current.node] = s.stamp + 1
+	for _, edge := range s.edges[current.node] {
+		newDist := current.priority + edge.weight
+		if s.reached(edge.to) && s.dist[edge.to] <= newDist {
+			continue
+		}
+		s.state[edge.to] = s.stamp
+		s.dist[edge.to] = newDist
+		s.prev[edge.to] = chEdge{to: current.node, weight: edge.weight, middle: edge.middle}
+		s.open.push(chPriority{node: edge.to, priority: newDist})
+	}
+	return current.node
+}
+
+// Type chQuery holds the state of both directions of a query.
+type chQuery struct {
+	forward  *chSearch
+	backward *chSearch
+}
+
+// FindPath finds the cheapest path between the start and end node using the hierarchy. The path is
+// returned in the correct order, starting with the start node, and contains all nodes, not just the
+// ones in the hierarchy. Its cost is the same as that of the one FindPath would find without a
+// heuristic. If start and end are the same, the path contains only that node.
+func (ch *ContractionHierarchy) FindPath(start, end *Node) (path []*Node, err error) {
+	// Handle panics internally.
+	defer getPanicHandler(&err)()
+
+	startIdx, found := ch.index[start]
+	if !found {
+		return []*Node{}, fmt.Errorf("input sanitation: start node not in hierarchy")
+	}
+	endIdx, found := ch.index[end]
+	if !found {
+		return []*Node{}, fmt.Errorf("input sanitation: end node not in hierarchy")
+	}
+
+	// Reuse the state of an earlier query, if possible.
+	query := ch.queries.Get().(*chQuery)
+	defer ch.queries.Put(query)
+	forward, backward := query.forward, query.backward
+	forward.start(startIdx)
+	backward.start(endIdx)
+	best, meet := -1, -1
+	for {
+		// Continue with the direction that has the cheaper node on its open list. Stop once no
+		// direction can improve on the best path found so far.
+		search, other := forward, backward
+		forwardKey, backwardKey := forward.minKey(), backward.minKey()
+		if forwardKey < 0 || (backwardKey >= 0 && backwardKey < forwardKey) {
+			search, other = backward, forward
+			forwardKey = backwardKey
+		}
+		if forwardKey < 0 || (best >= 0 && forwardKey >= best) {
+			break
+		}
+		settled := search.step()
+		if other.reached(settled) {
+			total := search.dist[settled] + other.dist[settled]
+			if best < 0 || total < best {
+				best, meet = total, settled
+			}
+		}
+	}
+	if best < 0 {
+		return []*Node{}, fmt.Errorf("no path found: %w", errNoPath)
+	}
+
+	// Collect the connections in the hierarchy from the start to the meeting node and from there
+	// to the end. Then, replace shortcuts by the connections they bypass.
+	forwardEdges := []chEdge{}
+	for curr := meet; curr != startIdx; {
+		edge := forward.prev[curr]
+		forwardEdges = append(forwardEdges, chEdge{to: curr, middle: edge.middle})
+		curr = edge.to
+	}
+	path = []*Node{start}
+	from := startIdx
+	for idx := len(forwardEdges) - 1; idx >= 0; idx-- {
+		path = ch.unpack(path, from, forwardEdges[idx].to, forwardEdges[idx].middle)
+		from = forwardEdges[idx].to
+	}
+	for curr := meet; curr != endIdx; {
+		edge := backward.prev[curr]
+		path = ch.unpack(path, curr, edge.to, edge.middle)
+		curr = edge.to
+	}
+	return path, nil
+}
+
+// Method unpack appends all nodes on the connection from one node to another to the path, apart
+// from the first one. Shortcuts are unpacked recursively.
+func (ch *ContractionHierarchy) unpack(path []*Node, from, to, middle int) []*Node {
+	if middle < 0 {
+		return append(path, ch.nodes[to])
+	}
+	path = ch.unpack(path, from, middle, ch.edgeMiddle(from, middle))
+	return ch.unpack(path, middle, to, ch.edgeMiddle(middle, to))
+}
+
+// Method edgeMiddle determines the node bypassed by the hierarchy connection between two nodes.
+// Such a connection is stored with the less important of the two nodes.
+func (ch *ContractionHierarchy) edgeMiddle(from, to int) int {
+	for _, edge := range ch.up[from] {
+		if edge.to == to {
+			return edge.middle
+		}
+	}
+	for _, edge := range ch.down[to] {
+		if edge.to == from {
+			return edge.middle
+		}
+	}
+	panic(Error{"connection missing in contraction hierarchy"})
+}
@@ -0,0 +1,133 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure that each node in a path is connected to the next one.
+func assertPathConnected(t *testing.T, path []*Node) {
+	for idx := 1; idx < len(path); idx++ {
+		connected := path[idx-1].connectedTo(path[idx])
+		assert.True(t, connected, "%s -> %s", path[idx-1].ID, path[idx].ID)
+	}
+}
+
+func TestContractionHierarchyMatchesFindPath(t *testing.T) {
+	diagonals := [][2]int{[2]int{1, 1}, [2]int{-1, -1}}
+	for _, connections := range [][][2]int{fourNeighbours, append(diagonals, fourNeighbours...)} {
+		graph, posToNode, err := CreateRegular2DGrid([2]int{8, 8}, connections, "heaped", 0)
+		assert.NoError(t, err)
+		for pos, node := range posToNode {
+			node.Cost = (pos[0]*7+pos[1]*13)%10 + 1
+		}
+		// Add some obstacles.
+		for _, pos := range [][2]int{[2]int{3, 2}, [2]int{3, 3}, [2]int{3, 4}, [2]int{3, 5}} {
+			node := posToNode[pos]
+			for _, neigh := range node.connections {
+				neigh.RemoveConnection(node)
+			}
+		}
+
+		ch, err := NewContractionHierarchy(graph)
+		assert.NoError(t, err)
+
+		for _, startPos := range [][2]int{[2]int{0, 0}, [2]int{5, 3}, [2]int{2, 4}} {
+			for _, endPos := range [][2]int{[2]int{7, 7}, [2]int{0, 7}, [2]int{4, 4}} {
+				start, end := posToNode[startPos], posToNode[endPos]
+				path, err := ch.FindPath(start, end)
+				assert.NoError(t, err)
+				assert.Equal(t, start, path[0])
+				assert.Equal(t, end, path[len(path)-1])
+				assertPathConnected(t, path)
+
+				reference, err := FindPath(graph, start, end, zeroHeuristic)
+				assert.NoError(t, err)
+				assert.Equal(t, NewPath(reference, nil).Cost, NewPath(path, nil).Cost)
+			}
+		}
+	}
+}
+
+func TestContractionHierarchyOneWay(t *testing.T) {
+	graph, nodeA, nodeB, nodeC := setUpChain(t)
+
+	ch, err := NewContractionHierarchy(graph)
+	assert.NoError(t, err)
+
+	path, err := ch.FindPath(nodeA, nodeC)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{nodeA, nodeB, nodeC}, path)
+
+	path, err = ch.FindPath(nodeB, nodeB)
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{nodeB}, path)
+
+	// Connections are only one way.
+	_, err = ch.FindPath(nodeC, nodeA)
+	assert.Error(t, err)
+}
+
+func TestContractionHierarchyFailure(t *testing.T) {
+	graph, nodeA, _, _ := setUpChain(t)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+	// Connections leaving the graph are ignored.
+	nodeA.AddConnection(outside)
+
+	ch, err := NewContractionHierarchy(graph)
+	assert.NoError(t, err)
+
+	_, err = ch.FindPath(outside, nodeA)
+	assert.Error(t, err)
+	_, err = ch.FindPath(nodeA, outside)
+	assert.Error(t, err)
+
+	_, err = NewContractionHierarchy(&failingApplyGraph{})
+	assert.Error(t, err)
+}
+
+func TestContractionHierarchyFindPathMissingConnection(t *testing.T) {
+	graph, nodeA, _, nodeC := setUpChain(t)
+	ch, err := NewContractionHierarchy(graph)
+	assert.NoError(t, err)
+
+	// Pretend that the connection leaving a is a shortcut bypassing c. There is no connection
+	// between a and c, which makes unpacking the path fail.
+	up := ch.up[ch.index[nodeA]]
+	assert.Equal(t, 1, len(up))
+	up[0].middle = ch.index[nodeC]
+
+	_, err = ch.FindPath(nodeA, nodeC)
+	assert.Error(t, err)
+}
+
+func TestContractionHierarchyMissingConnectionPanics(t *testing.T) {
+	ch := &ContractionHierarchy{up: make([][]chEdge, 2), down: make([][]chEdge, 2)}
+
+	defer func() {
+		err, wasError := recover().(Error)
+		assert.True(t, wasError)
+		assert.Error(t, err)
+	}()
+
+	ch.edgeMiddle(0, 1)
+}
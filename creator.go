@@ -20,6 +20,8 @@ package astar
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 )
 
 func dist2D(pos1, pos2 [2]int) int {
@@ -41,8 +43,9 @@ func dist2D(pos1, pos2 [2]int) int {
 //      [2]int{0, 1},
 //  }
 //
-// Also provide the name of the type of graph you want to obtain as input. This can be "default" or
-// "heaped". The heaped graph has a better performance but is a more complex data structure.
+// Also provide the name of the type of graph you want to obtain as input. This can be "default",
+// "heaped", or "bucket". The heaped graph has a better performance but is a more complex data
+// structure. The bucket graph is even faster if costs are small integers, see BucketGraph.
 //
 // CreateRegular2DGrid returns three values:
 // 1. A graph object suitable for paht finding via FindPath.
@@ -57,14 +60,9 @@ func CreateRegular2DGrid(
 	neighbours := len(connections)
 	gridSize := size[0] * size[1]
 
-	var graph GraphOps
-	switch graphType {
-	case "default":
-		graph = NewGraph(gridSize)
-	case "heaped":
-		graph = NewHeapedGraph(gridSize)
-	default:
-		return nil, nil, fmt.Errorf("unknown graph type, need 'default' or 'heaped'")
+	graph, err := newGraphOfType(graphType, gridSize)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// We remember the node for each position. This makes creating connections easier later on.
@@ -105,11 +103,30 @@ func CreateRegular2DGrid(
 	return graph, posToNode, nil
 }
 
+// Function newGraphOfType creates an empty graph of the type with the given name. Supported names
+// are "default", "heaped", and "bucket".
+func newGraphOfType(graphType string, estimatedSize int) (GraphOps, error) {
+	switch graphType {
+	case "default":
+		return NewGraph(estimatedSize), nil
+	case "heaped":
+		return NewHeapedGraph(estimatedSize), nil
+	case "bucket":
+		return NewBucketGraph(estimatedSize), nil
+	default:
+		return nil, fmt.Errorf("unknown graph type, need 'default', 'heaped', or 'bucket'")
+	}
+}
+
 // CreateConstantHeuristic2D creates a constant heuristic for a regular 2D grid. It takes a map from
 // node positions to node pointers and the desired end position and returns a simple, constant
 // heuristic that estimates the remaining cost as the line-of-sight distance to the desired
 // destination. Heuristics must return a value for all nodes, even ones they don't remember. For
 // such nodes, it returns `defaultVal`.
+//
+// This heuristic ignores node costs and the connections of the grid. Thus, it over-estimates for
+// nodes with costs below one. Use CreateConnectionsHeuristic2D for a heuristic that never
+// over-estimates.
 func CreateConstantHeuristic2D(
 	posMap map[[2]int]*Node, dest [2]int, defaultVal int,
 ) (Heuristic, error) {
@@ -126,3 +143,309 @@ func CreateConstantHeuristic2D(
 
 	return heuristic.Heuristic(0), nil
 }
+
+// Metric2D determines the distance between two positions on a regular 2D grid. It is used to build
+// heuristics via CreateScaledHeuristic2D.
+//
+// There is no octile metric. Moving onto a node costs the same for diagonal and straight moves,
+// so weighting diagonal moves higher over-estimates the actual costs. Use ChebyshevDistance2D for
+// grids with diagonal connections instead, it never over-estimates.
+type Metric2D = func(pos1, pos2 [2]int) int
+
+func absInt(val int) int {
+	if val < 0 {
+		return -val
+	}
+	return val
+}
+
+// ManhattanDistance2D is the number of moves needed to get from one position to another one if only
+// horizontal and vertical moves are possible.
+func ManhattanDistance2D(pos1, pos2 [2]int) int {
+	return absInt(pos1[0]-pos2[0]) + absInt(pos1[1]-pos2[1])
+}
+
+// ChebyshevDistance2D is the number of moves needed to get from one position to another one if
+// diagonal moves are possible in addition to horizontal and vertical ones.
+func ChebyshevDistance2D(pos1, pos2 [2]int) int {
+	xDist := absInt(pos1[0] - pos2[0])
+	yDist := absInt(pos1[1] - pos2[1])
+	if xDist > yDist {
+		return xDist
+	}
+	return yDist
+}
+
+// EuclideanDistance2D is the line-of-sight distance between two positions rounded down. This is the
+// metric used by CreateConstantHeuristic2D.
+func EuclideanDistance2D(pos1, pos2 [2]int) int {
+	return dist2D(pos1, pos2)
+}
+
+// MetricForConnections2D determines a metric suitable for a regular 2D grid created with the given
+// connections. The metric is a lower bound for the number of moves needed to get from one position
+// to another one. For the connections in the example for CreateRegular2DGrid, this is the Manhattan
+// distance. If diagonal connections are added, this is the Chebyshev distance. Arbitrary
+// displacements are supported, too. An error is returned if there are no non-zero displacements.
+func MetricForConnections2D(connections [][2]int) (Metric2D, error) {
+	maxX, maxY, maxSum := 0, 0, 0
+	for _, disp := range connections {
+		xDisp, yDisp := absInt(disp[0]), absInt(disp[1])
+		if xDisp > maxX {
+			maxX = xDisp
+		}
+		if yDisp > maxY {
+			maxY = yDisp
+		}
+		if xDisp+yDisp > maxSum {
+			maxSum = xDisp + yDisp
+		}
+	}
+	if maxSum == 0 {
+		return nil, fmt.Errorf("cannot determine metric without non-zero displacements")
+	}
+	// Number of moves needed at least to cover a distance if each move covers at most maxStep.
+	movesFor := func(dist, maxStep int) int {
+		if maxStep == 0 {
+			// No move covers any distance in this direction. Such positions cannot be reached at
+			// all, so any estimate is fine.
+			return 0
+		}
+		return (dist + maxStep - 1) / maxStep
+	}
+	metric := func(pos1, pos2 [2]int) int {
+		xDist := absInt(pos1[0] - pos2[0])
+		yDist := absInt(pos1[1] - pos2[1])
+		moves := movesFor(xDist, maxX)
+		if yMoves := movesFor(yDist, maxY); yMoves > moves {
+			moves = yMoves
+		}
+		if sumMoves := movesFor(xDist+yDist, maxSum); sumMoves > moves {
+			moves = sumMoves
+		}
+		return moves
+	}
+	return metric, nil
+}
+
+// CreateScaledHeuristic2D creates a constant heuristic for a regular 2D grid, just like
+// CreateConstantHeuristic2D. In contrast to that, the estimate for each node is the distance to the
+// destination according to `metric` multiplied by the minimum cost of all nodes in `posMap`. If
+// the metric never over-estimates the number of moves needed, as is the case for the one obtained
+// via MetricForConnections2D, the heuristic never over-estimates the actual costs. In particular,
+// it estimates zero everywhere if there are nodes with zero cost.
+//
+// Note that the heuristic only knows the costs at the time it is created. Create a new one after
+// lowering node costs.
+func CreateScaledHeuristic2D(
+	posMap map[[2]int]*Node, dest [2]int, metric Metric2D, defaultVal int,
+) (Heuristic, error) {
+	minCost := -1
+	for _, node := range posMap {
+		if minCost < 0 || node.Cost < minCost {
+			minCost = node.Cost
+		}
+	}
+
+	heuristic := ConstantHeuristic{}
+	for pos, node := range posMap {
+		err := heuristic.AddNode(node, minCost*metric(pos, dest))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return heuristic.Heuristic(defaultVal), nil
+}
+
+// CreateConnectionsHeuristic2D creates a heuristic for a regular 2D grid that has been created with
+// the given connections. It combines MetricForConnections2D and CreateScaledHeuristic2D. Thus, the
+// heuristic never over-estimates the actual costs.
+func CreateConnectionsHeuristic2D(
+	posMap map[[2]int]*Node, dest [2]int, connections [][2]int, defaultVal int,
+) (Heuristic, error) {
+	metric, err := MetricForConnections2D(connections)
+	if err != nil {
+		return nil, err
+	}
+	return CreateScaledHeuristic2D(posMap, dest, metric, defaultVal)
+}
+
+// Function distND determines the line-of-sight distance between two positions with any number of
+// dimensions, rounded down. Both positions need the same number of dimensions.
+func distND(pos1, pos2 []int) int {
+	sum := 0.
+	for dim := range pos1 {
+		dist := float64(pos1[dim] - pos2[dim])
+		sum += dist * dist
+	}
+	return int(math.Floor(math.Sqrt(sum)))
+}
+
+// GridIndexND determines the index of the node at a position in a grid created via
+// CreateRegularNDGrid with the given size. The first coordinate changes slowest, just like for
+// CSRGridIndex2D. Positions outside of the grid result in a negative index.
+func GridIndexND(size []int, pos []int) int {
+	if len(pos) != len(size) {
+		return -1
+	}
+	idx := 0
+	for dim, coord := range pos {
+		if coord < 0 || coord >= size[dim] {
+			return -1
+		}
+		idx = idx*size[dim] + coord
+	}
+	return idx
+}
+
+// GridPosND determines the position of the node with an index in a grid created via
+// CreateRegularNDGrid with the given size. It is the inverse of GridIndexND.
+func GridPosND(size []int, idx int) []int {
+	pos := make([]int, len(size))
+	for dim := len(size) - 1; dim >= 0; dim-- {
+		pos[dim] = idx % size[dim]
+		idx /= size[dim]
+	}
+	return pos
+}
+
+// Function createRegularGrid creates a regular grid with any number of dimensions. Nodes are
+// returned in the order of their indices, see GridIndexND. The function `id` names each node.
+func createRegularGrid(
+	size []int, connections [][]int, graphType string, defaultCost int, id func([]int) string,
+) (GraphOps, []*Node, error) {
+	gridSize := 1
+	for _, length := range size {
+		if length < 0 {
+			return nil, nil, fmt.Errorf("negative grid size")
+		}
+		gridSize *= length
+	}
+	for _, disp := range connections {
+		if len(disp) != len(size) {
+			return nil, nil, fmt.Errorf("displacement %v does not match grid dimensions", disp)
+		}
+	}
+	graph, err := newGraphOfType(graphType, gridSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := make([]*Node, 0, gridSize)
+	for idx := 0; idx < gridSize; idx++ {
+		node, err := NewNode(id(GridPosND(size, idx)), defaultCost, len(connections), nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		nodes = append(nodes, node)
+		graph.Add(node)
+	}
+
+	neighPos := make([]int, len(size))
+	for idx, node := range nodes {
+		pos := GridPosND(size, idx)
+		for _, disp := range connections {
+			for dim := range pos {
+				neighPos[dim] = pos[dim] + disp[dim]
+			}
+			// Only add connections if the neighbour actually exists!
+			if neighIdx := GridIndexND(size, neighPos); neighIdx >= 0 {
+				node.AddPairwiseConnection(nodes[neighIdx])
+			}
+		}
+	}
+	return graph, nodes, nil
+}
+
+// CreateRegular3DGrid creates a regular 3D grid of connected nodes in a graph suitable for path
+// finding, e.g. for a building with several floors. It works just like CreateRegular2DGrid but
+// with three coordinates. Node IDs look like "x:1,y:2,z:3".
+//
+// CreateRegular3DGrid returns three values:
+// 1. A graph object suitable for path finding via FindPath.
+// 2. A map from node positions to node pointers.
+// 3. An error value in case there were problems.
+func CreateRegular3DGrid(
+	size [3]int, connections [][3]int, graphType string, defaultCost int,
+) (GraphOps, map[[3]int]*Node, error) {
+	connectionsND := make([][]int, 0, len(connections))
+	for _, disp := range connections {
+		connectionsND = append(connectionsND, []int{disp[0], disp[1], disp[2]})
+	}
+	id := func(pos []int) string {
+		return fmt.Sprintf("x:%d,y:%d,z:%d", pos[0], pos[1], pos[2])
+	}
+	graph, nodes, err := createRegularGrid(
+		size[:], connectionsND, graphType, defaultCost, id,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	posToNode := make(map[[3]int]*Node, len(nodes))
+	for idx, node := range nodes {
+		pos := GridPosND(size[:], idx)
+		posToNode[[3]int{pos[0], pos[1], pos[2]}] = node
+	}
+	return graph, posToNode, nil
+}
+
+// CreateConstantHeuristic3D creates a constant heuristic for a regular 3D grid. It works just like
+// CreateConstantHeuristic2D but with three coordinates. For nodes it does not remember, it returns
+// `defaultVal`.
+func CreateConstantHeuristic3D(
+	posMap map[[3]int]*Node, dest [3]int, defaultVal int,
+) (Heuristic, error) {
+	heuristic := ConstantHeuristic{}
+	for pos, node := range posMap {
+		err := heuristic.AddNode(node, distND(pos[:], dest[:]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return heuristic.Heuristic(defaultVal), nil
+}
+
+// CreateRegularNDGrid creates a regular grid with any number of dimensions. It works just like
+// CreateRegular2DGrid but each position and each displacement is a slice with one entry per
+// dimension. Since slices cannot be used as map keys, nodes are returned as a slice instead. Use
+// GridIndexND and GridPosND to convert between positions and indices. Node IDs look like
+// "pos:1,2,3,4".
+//
+// CreateRegularNDGrid returns three values:
+// 1. A graph object suitable for path finding via FindPath.
+// 2. A slice of all nodes, the node at a position has the index GridIndexND returns for it.
+// 3. An error value in case there were problems, e.g. with displacements of the wrong length.
+func CreateRegularNDGrid(
+	size []int, connections [][]int, graphType string, defaultCost int,
+) (GraphOps, []*Node, error) {
+	id := func(pos []int) string {
+		coords := make([]string, 0, len(pos))
+		for _, coord := range pos {
+			coords = append(coords, strconv.Itoa(coord))
+		}
+		return "pos:" + strings.Join(coords, ",")
+	}
+	return createRegularGrid(size, connections, graphType, defaultCost, id)
+}
+
+// CreateConstantHeuristicND creates a constant heuristic for a regular grid created via
+// CreateRegularNDGrid with the given size. It takes the nodes of the grid and the desired end
+// position and estimates the remaining cost as the line-of-sight distance to the destination, just
+// like CreateConstantHeuristic2D. For nodes it does not remember, it returns `defaultVal`.
+func CreateConstantHeuristicND(
+	size []int, nodes []*Node, dest []int, defaultVal int,
+) (Heuristic, error) {
+	if len(dest) != len(size) {
+		return nil, fmt.Errorf("destination does not match grid dimensions")
+	}
+	heuristic := ConstantHeuristic{}
+	for idx, node := range nodes {
+		err := heuristic.AddNode(node, distND(GridPosND(size, idx), dest))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return heuristic.Heuristic(defaultVal), nil
+}
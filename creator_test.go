@@ -51,7 +51,7 @@ func TestDist2D(t *testing.T) {
 }
 
 func TestCreateRegular2DGridSuccess(t *testing.T) {
-	for _, graphType := range []string{"default", "heaped"} {
+	for _, graphType := range []string{"default", "heaped", "bucket"} {
 		size := [2]int{10, 10}
 		connections := [][2]int{
 			[2]int{-1, 0},
@@ -78,7 +78,7 @@ func TestCreateRegular2DGridWrongTypeFailure(t *testing.T) {
 }
 
 func TestCreateRegular2DGridNodeCreationFailure(t *testing.T) {
-	for _, graphType := range []string{"default", "heaped"} {
+	for _, graphType := range []string{"default", "heaped", "bucket"} {
 		size := [2]int{10, 10}
 		connections := [][2]int{}
 
@@ -118,3 +118,188 @@ func TestCreateConstantHeuristic2DFailure(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestMetrics2D(t *testing.T) {
+	origin := [2]int{0, 0}
+	for _, pos := range [][2]int{[2]int{3, -4}, [2]int{-4, 3}} {
+		assert.Equal(t, 7, ManhattanDistance2D(origin, pos))
+		assert.Equal(t, 4, ChebyshevDistance2D(origin, pos))
+		assert.Equal(t, 5, EuclideanDistance2D(origin, pos))
+	}
+	assert.Equal(t, 0, ChebyshevDistance2D(origin, origin))
+}
+
+func TestMetricForConnections2D(t *testing.T) {
+	origin := [2]int{0, 0}
+	positions := [][2]int{
+		[2]int{0, 0},
+		[2]int{3, -4},
+		[2]int{-4, 3},
+		[2]int{7, 0},
+	}
+
+	metric, err := MetricForConnections2D(fourNeighbours)
+	assert.NoError(t, err)
+	for _, pos := range positions {
+		assert.Equal(t, ManhattanDistance2D(origin, pos), metric(origin, pos))
+	}
+
+	diagonals := [][2]int{[2]int{1, 1}, [2]int{1, -1}, [2]int{-1, 1}, [2]int{-1, -1}}
+	metric, err = MetricForConnections2D(append(diagonals, fourNeighbours...))
+	assert.NoError(t, err)
+	for _, pos := range positions {
+		assert.Equal(t, ChebyshevDistance2D(origin, pos), metric(origin, pos))
+	}
+
+	// Knight's moves cover at most three fields per move, at most two in each direction.
+	knight := [][2]int{[2]int{1, 2}, [2]int{2, 1}, [2]int{-1, -2}, [2]int{-2, -1}}
+	metric, err = MetricForConnections2D(knight)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, metric(origin, [2]int{7, 0}))
+	assert.Equal(t, 3, metric(origin, [2]int{3, -4}))
+
+	// Moving only horizontally never reaches other rows. Any estimate is fine then.
+	metric, err = MetricForConnections2D([][2]int{[2]int{1, 0}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, metric(origin, [2]int{3, 0}))
+	assert.Equal(t, 7, metric(origin, [2]int{3, -4}))
+
+	_, err = MetricForConnections2D([][2]int{[2]int{0, 0}})
+	assert.Error(t, err)
+	_, err = MetricForConnections2D(nil)
+	assert.Error(t, err)
+}
+
+func TestCreateScaledHeuristic2D(t *testing.T) {
+	graph, posMap, err := CreateRegular2DGrid([2]int{5, 5}, fourNeighbours, "heaped", 2)
+	assert.NoError(t, err)
+	endPos := [2]int{4, 4}
+	unknownNode, err := NewNode("unknown", 0, 0, nil)
+	assert.NoError(t, err)
+
+	heuristic, err := CreateScaledHeuristic2D(posMap, endPos, ManhattanDistance2D, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 16, heuristic(posMap[[2]int{0, 0}]))
+	assert.Equal(t, 3, heuristic(unknownNode))
+	report, err := CheckHeuristic(graph, posMap[endPos], heuristic)
+	assert.NoError(t, err)
+	assert.True(t, report.Admissible())
+	assert.True(t, report.Consistent())
+
+	// A single free node makes the heuristic estimate zero everywhere.
+	posMap[[2]int{2, 2}].Cost = 0
+	heuristic, err = CreateScaledHeuristic2D(posMap, endPos, ManhattanDistance2D, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, heuristic(posMap[[2]int{0, 0}]))
+}
+
+func TestCreateScaledHeuristic2DFailure(t *testing.T) {
+	node, err := NewNode("node", 1, 0, nil)
+	assert.NoError(t, err)
+	posMap := map[[2]int]*Node{
+		[2]int{0, 0}: node,
+		[2]int{5, 5}: node, // No node must be added more than once to a heuristic.
+	}
+
+	_, err = CreateScaledHeuristic2D(posMap, [2]int{10, 1}, ManhattanDistance2D, 0)
+
+	assert.Error(t, err)
+}
+
+func TestCreateConnectionsHeuristic2D(t *testing.T) {
+	for _, defaultCost := range []int{0, 1, 5} {
+		graph, posMap, err := CreateRegular2DGrid(
+			[2]int{6, 6}, fourNeighbours, "default", defaultCost,
+		)
+		assert.NoError(t, err)
+		endPos := [2]int{5, 2}
+
+		heuristic, err := CreateConnectionsHeuristic2D(posMap, endPos, fourNeighbours, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 7*defaultCost, heuristic(posMap[[2]int{0, 0}]))
+		report, err := CheckHeuristic(graph, posMap[endPos], heuristic)
+		assert.NoError(t, err)
+		assert.True(t, report.Admissible(), report.ToString())
+		assert.True(t, report.Consistent(), report.ToString())
+	}
+
+	_, err := CreateConnectionsHeuristic2D(map[[2]int]*Node{}, [2]int{}, nil, 0)
+	assert.Error(t, err)
+}
+
+func TestGridIndexND(t *testing.T) {
+	size := []int{2, 3, 4}
+	for idx := 0; idx < 24; idx++ {
+		assert.Equal(t, idx, GridIndexND(size, GridPosND(size, idx)))
+	}
+	assert.Equal(t, []int{1, 2, 3}, GridPosND(size, 23))
+	csrIdx := CSRGridIndex2D([2]int{3, 5}, [2]int{2, 1})
+	assert.Equal(t, csrIdx, GridIndexND([]int{3, 5}, []int{2, 1}))
+	assert.Equal(t, -1, GridIndexND(size, []int{0, 3, 0}))
+	assert.Equal(t, -1, GridIndexND(size, []int{0, -1, 0}))
+	assert.Equal(t, -1, GridIndexND(size, []int{0, 0}))
+}
+
+func TestCreateRegular3DGrid(t *testing.T) {
+	connections := [][3]int{
+		[3]int{1, 0, 0},
+		[3]int{0, 1, 0},
+		[3]int{0, 0, 1},
+	}
+	for _, graphType := range []string{"default", "heaped", "bucket"} {
+		graph, posToNode, err := CreateRegular3DGrid([3]int{3, 4, 5}, connections, graphType, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 60, graph.Len())
+		assert.Equal(t, 60, len(posToNode))
+		// Connections are pairwise.
+		assert.Equal(t, 6, len(posToNode[[3]int{1, 1, 1}].connections))
+		assert.Equal(t, 3, len(posToNode[[3]int{0, 0, 0}].connections))
+		assert.Equal(t, "x:2,y:3,z:4", posToNode[[3]int{2, 3, 4}].ID)
+
+		start, end := [3]int{0, 0, 0}, [3]int{2, 3, 4}
+		heuristic, err := CreateConstantHeuristic3D(posToNode, end, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 5, heuristic(posToNode[start]))
+		path, err := FindPath(graph, posToNode[start], posToNode[end], heuristic)
+		assert.NoError(t, err)
+		assert.Equal(t, 10, len(path))
+	}
+
+	_, _, err := CreateRegular3DGrid([3]int{3, 4, 5}, connections, "unknownType", 0)
+	assert.Error(t, err)
+	_, _, err = CreateRegular3DGrid([3]int{3, 4, 5}, connections, "heaped", -1)
+	assert.Error(t, err)
+}
+
+func TestCreateRegularNDGrid(t *testing.T) {
+	size := []int{2, 2, 2, 3}
+	connections := [][]int{
+		[]int{1, 0, 0, 0},
+		[]int{0, 1, 0, 0},
+		[]int{0, 0, 1, 0},
+		[]int{0, 0, 0, 1},
+	}
+	graph, nodes, err := CreateRegularNDGrid(size, connections, "heaped", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 24, graph.Len())
+	assert.Equal(t, 24, len(nodes))
+	assert.Equal(t, "pos:1,0,1,2", nodes[GridIndexND(size, []int{1, 0, 1, 2})].ID)
+	assert.Equal(t, 5, len(nodes[GridIndexND(size, []int{0, 0, 0, 1})].connections))
+
+	end := []int{1, 1, 1, 2}
+	heuristic, err := CreateConstantHeuristicND(size, nodes, end, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, heuristic(nodes[0]))
+	path, err := FindPath(graph, nodes[0], nodes[GridIndexND(size, end)], heuristic)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, len(path))
+
+	_, err = CreateConstantHeuristicND(size, nodes, []int{1, 1}, 0)
+	assert.Error(t, err)
+	_, _, err = CreateRegularNDGrid(size, [][]int{[]int{1, 0}}, "heaped", 1)
+	assert.Error(t, err)
+	_, _, err = CreateRegularNDGrid([]int{2, -1}, nil, "heaped", 1)
+	assert.Error(t, err)
+	_, _, err = CreateRegularNDGrid(size, connections, "unknownType", 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,305 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"math"
+
+	goheap "container/heap"
+)
+
+// CSRGraph is an immutable graph stored in the compressed sparse row format. Nodes are identified
+// by dense indices from 0 to Len()-1. The connections of all nodes are stored in a single array and
+// the costs of all nodes in another one. That needs far less memory than a Node with its own
+// connections and is much friendlier to the CPU cache. Obtain one via NewCSRGraph or
+// CreateRegular2DCSRGrid. Use a CSRSearcher to find paths.
+//
+// Costs work just as for FindPath: moving onto a node costs that node's cost. Since the graph never
+// changes, it can be used by several searchers at the same time.
+type CSRGraph struct {
+	// Member offsets holds, for each node, the position of its first connection in targets. It has
+	// one more entry than there are nodes. The last one is the total number of connections.
+	offsets []int32
+	// Member targets holds the indices of the nodes connected to, grouped by the node connected
+	// from.
+	targets []int32
+	// Member costs holds the cost of each node.
+	costs []int32
+}
+
+// Each search uses two stamps, one for reached and one for closed nodes.
+const csrStampsPerSearch = 2
+
+// Function newCSRGraph allocates a graph for a number of nodes. Connections have to be added via
+// addConnections in the order of the nodes.
+func newCSRGraph(numNodes, numConnectionsHint int) (*CSRGraph, error) {
+	if numNodes > math.MaxInt32 {
+		return nil, fmt.Errorf("too many nodes for a CSR graph: %d", numNodes)
+	}
+	graph := &CSRGraph{
+		offsets: make([]int32, 1, numNodes+1),
+		targets: make([]int32, 0, numConnectionsHint),
+		costs:   make([]int32, numNodes),
+	}
+	return graph, nil
+}
+
+// Method addConnections adds the connections of the next node and sets its cost.
+func (g *CSRGraph) addConnections(cost int, targets []int32) error {
+	if cost < 0 || cost > math.MaxInt32 {
+		return fmt.Errorf("cost %d not supported by a CSR graph", cost)
+	}
+	if len(g.targets)+len(targets) > math.MaxInt32 {
+		return fmt.Errorf("too many connections for a CSR graph")
+	}
+	g.costs[len(g.offsets)-1] = int32(cost)
+	g.targets = append(g.targets, targets...)
+	g.offsets = append(g.offsets, int32(len(g.targets)))
+	return nil
+}
+
+// NewCSRGraph converts a graph of nodes into a CSRGraph. It also returns the nodes in the order of
+// their indices, i.e. the node with index idx is at position idx. Nodes are sorted by their IDs.
+// Connections to nodes outside the graph are ignored. Later changes to the nodes do not affect the
+// CSRGraph.
+func NewCSRGraph(graph GraphOps) (*CSRGraph, []*Node, error) {
+	nodes := make([]*Node, 0, graph.Len())
+	err := graph.Apply(func(node *Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	// Sort to make indices reproducible.
+	sortNodesByID(nodes)
+	index := make(map[*Node]int32, len(nodes))
+	numConnections := 0
+	for idx, node := range nodes {
+		index[node] = int32(idx)
+		numConnections += len(node.connections)
+	}
+
+	csr, err := newCSRGraph(len(nodes), numConnections)
+	if err != nil {
+		return nil, nil, err
+	}
+	targets := []int32{}
+	for _, node := range nodes {
+		targets = targets[:0]
+		for _, neigh := range node.connections {
+			if neighIdx, inGraph := index[neigh]; inGraph {
+				targets = append(targets, neighIdx)
+			}
+		}
+		if err := csr.addConnections(node.Cost, targets); err != nil {
+			return nil, nil, err
+		}
+	}
+	return csr, nodes, nil
+}
+
+// CreateRegular2DCSRGrid creates a regular 2D grid as a CSRGraph without ever creating any nodes.
+// It takes the same size and connections as CreateRegular2DGrid and connects the same nodes. Each
+// node's cost is determined by calling the provided cost function with the node's position. Use
+// CSRGridIndex2D and CSRGridPos2D to convert between positions and indices.
+func CreateRegular2DCSRGrid(
+	size [2]int, connections [][2]int, cost func(pos [2]int) int,
+) (*CSRGraph, error) {
+	if size[0] < 0 || size[1] < 0 {
+		return nil, fmt.Errorf("negative grid size")
+	}
+	numNodes := size[0] * size[1]
+	graph, err := newCSRGraph(numNodes, len(connections)*numNodes)
+	if err != nil {
+		return nil, err
+	}
+	inGrid := func(pos [2]int) bool {
+		return pos[0] >= 0 && pos[0] < size[0] && pos[1] >= 0 && pos[1] < size[1]
+	}
+	targets := []int32{}
+	// Iterate in the order of the indices.
+	for x := 0; x < size[0]; x++ {
+		for y := 0; y < size[1]; y++ {
+			targets = targets[:0]
+			// Connections are pairwise, just like for CreateRegular2DGrid. Thus, each node is
+			// connected in the direction of each displacement and the opposite one.
+			for _, factor := range []int{1, -1} {
+				for _, disp := range connections {
+					neighPos := [2]int{x + factor*disp[0], y + factor*disp[1]}
+					if !inGrid(neighPos) || neighPos == [2]int{x, y} {
+						continue
+					}
+					targets = appendUnique(targets, int32(CSRGridIndex2D(size, neighPos)))
+				}
+			}
+			if err := graph.addConnections(cost([2]int{x, y}), targets); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return graph, nil
+}
+
+// Function appendUnique appends a value to a slice unless the slice already contains it.
+func appendUnique(values []int32, value int32) []int32 {
+	for _, known := range values {
+		if known == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// CSRGridIndex2D determines the index of the node at a position in a grid created via
+// CreateRegular2DCSRGrid with the given size.
+func CSRGridIndex2D(size [2]int, pos [2]int) int {
+	return pos[0]*size[1] + pos[1]
+}
+
+// CSRGridPos2D determines the position of the node with an index in a grid created via
+// CreateRegular2DCSRGrid with the given size. It is the inverse of CSRGridIndex2D.
+func CSRGridPos2D(size [2]int, idx int) [2]int {
+	return [2]int{idx / size[1], idx % size[1]}
+}
+
+// Len determines the number of nodes.
+func (g *CSRGraph) Len() int {
+	return len(g.costs)
+}
+
+// Cost determines the cost of the node with an index.
+func (g *CSRGraph) Cost(idx int) int {
+	return int(g.costs[idx])
+}
+
+// Neighbours determines the indices of the nodes the node with an index is connected to. The
+// returned slice is part of the graph and must not be modified.
+func (g *CSRGraph) Neighbours(idx int) []int32 {
+	return g.targets[g.offsets[idx]:g.offsets[idx+1]]
+}
+
+// CSRHeuristic estimates the remaining cost from the node with an index to the end node. It is the
+// equivalent of a Heuristic for a CSRGraph.
+type CSRHeuristic func(idx int) int
+
+// CSRSearcher finds paths in a CSRGraph via the A* algorithm. It holds all state needed for a
+// search in arrays with one entry per node. Those arrays are allocated once and reused by every
+// search. Instead of being cleared, the entries a search writes are tagged with a stamp unique to
+// that search. Thus, a search only takes time for the nodes it explores. Obtain one via
+// NewCSRSearcher.
+//
+// A searcher must not be used by several goroutines at the same time. Create one per goroutine
+// instead, they can all share the same graph.
+type CSRSearcher struct {
+	graph *CSRGraph
+	// Member stamp identifies the current search. It is always odd. A node has been reached by the
+	// current search if its state is equal to the stamp. It has been closed if its state is one
+	// larger. Anything else means the node has not been reached.
+	stamp uint32
+	state []uint32
+	// Members cost and prev hold the tracked cost and the predecessor of each node reached.
+	cost []int
+	prev []int32
+	open chPriorityHeap
+}
+
+// NewCSRSearcher creates a searcher for a graph. See CSRSearcher for details.
+func NewCSRSearcher(graph *CSRGraph) *CSRSearcher {
+	return &CSRSearcher{
+		graph: graph,
+		stamp: 1,
+		state: make([]uint32, graph.Len()),
+		cost:  make([]int, graph.Len()),
+		prev:  make([]int32, graph.Len()),
+	}
+}
+
+// Method nextSearch obtains a new stamp for the next search. If all stamps have been used up, the
+// state is cleared so that stamps can be reused.
+func (s *CSRSearcher) nextSearch() {
+	if s.stamp >= math.MaxUint32-csrStampsPerSearch {
+		for idx := range s.state {
+			s.state[idx] = 0
+		}
+		s.stamp = 1
+	} else {
+		s.stamp += csrStampsPerSearch
+	}
+	s.open = s.open[:0]
+}
+
+// FindPath finds the cheapest path from the start node to the end node, both given by their
+// indices. It returns the indices of all nodes on the path, starting with the start node, and the
+// cost of the path. The cost of the start node is not included. If start and end are the same, the
+// path contains only that node.
+//
+// The heuristic may be evaluated more than once for a node. If the heuristic never over-estimates,
+// the path is one of the cheapest ones. Among equally cheap nodes, the one with the lowest index is
+// expanded first, which makes results reproducible.
+func (s *CSRSearcher) FindPath(start, end int, heuristic CSRHeuristic) ([]int, int, error) {
+	if start < 0 || start >= s.graph.Len() {
+		return []int{}, 0, fmt.Errorf("input sanitation: start index out of range")
+	}
+	if end < 0 || end >= s.graph.Len() {
+		return []int{}, 0, fmt.Errorf("input sanitation: end index out of range")
+	}
+	s.nextSearch()
+	reached, closed := s.stamp, s.stamp+1
+
+	s.state[start] = reached
+	s.cost[start] = defaultCost
+	s.prev[start] = -1
+	goheap.Push(&s.open, chPriority{node: start, priority: heuristic(start)})
+	for s.open.Len() != 0 && s.state[end] != closed {
+		current := goheap.Pop(&s.open).(chPriority).node
+		// Nodes can be on the open list several times. Only the cheapest entry counts.
+		if s.state[current] == closed {
+			continue
+		}
+		s.state[current] = closed
+		for _, neigh := range s.graph.Neighbours(current) {
+			if s.state[neigh] == closed {
+				continue
+			}
+			newCost := s.cost[current] + int(s.graph.costs[neigh])
+			if s.state[neigh] == reached && newCost >= s.cost[neigh] {
+				continue
+			}
+			s.state[neigh] = reached
+			s.cost[neigh] = newCost
+			s.prev[neigh] = int32(current)
+			goheap.Push(
+				&s.open, chPriority{node: int(neigh), priority: newCost + heuristic(int(neigh))},
+			)
+		}
+	}
+	if s.state[end] != closed {
+		return []int{}, 0, fmt.Errorf("no path found: %w", errNoPath)
+	}
+
+	path := []int{}
+	for curr := int32(end); curr >= 0; curr = s.prev[curr] {
+		path = append(path, int(curr))
+	}
+	for left, right := 0, len(path)-1; left < right; left, right = left+1, right-1 {
+		path[left], path[right] = path[right], path[left]
+	}
+	return path, s.cost[end], nil
+}
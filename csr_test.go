@@ -0,0 +1,199 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Function gridCost provides varying costs for grids in tests.
+func gridCost(pos [2]int) int {
+	return 1 + (pos[0]*3+pos[1])%4
+}
+
+func TestNewCSRGraph(t *testing.T) {
+	graph := NewGraph(0)
+	nodes := []*Node{}
+	for idx, cost := range []int{3, 1, 2} {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), cost, 0, nil)
+		assert.NoError(t, err)
+		graph.Add(node)
+		nodes = append(nodes, node)
+	}
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+	nodes[0].AddConnection(nodes[2])
+	nodes[0].AddConnection(outside)
+	nodes[0].AddConnection(nodes[1])
+	nodes[2].AddPairwiseConnection(nodes[1])
+
+	csr, csrNodes, err := NewCSRGraph(graph)
+	assert.NoError(t, err)
+	assert.Equal(t, nodes, csrNodes)
+	assert.Equal(t, 3, csr.Len())
+	assert.Equal(t, []int{3, 1, 2}, []int{csr.Cost(0), csr.Cost(1), csr.Cost(2)})
+	// Connections keep their order. Those to nodes outside the graph are dropped.
+	assert.Equal(t, []int32{2, 1}, csr.Neighbours(0))
+	assert.Equal(t, []int32{2}, csr.Neighbours(1))
+	assert.Equal(t, []int32{1}, csr.Neighbours(2))
+
+	// Later changes do not affect the CSR graph.
+	nodes[1].Cost = 10
+	nodes[1].AddConnection(nodes[0])
+	assert.Equal(t, 1, csr.Cost(1))
+	assert.Equal(t, []int32{2}, csr.Neighbours(1))
+}
+
+func TestNewCSRGraphFailure(t *testing.T) {
+	node, err := NewNode("node", 0, 0, nil)
+	assert.NoError(t, err)
+	graph := NewGraph(0)
+	graph.Add(node)
+
+	node.Cost = math.MaxInt32 + 1
+	_, _, err = NewCSRGraph(graph)
+	assert.Error(t, err)
+
+	_, _, err = NewCSRGraph(&failingApplyGraph{})
+	assert.Error(t, err)
+}
+
+func TestCreateRegular2DCSRGrid(t *testing.T) {
+	size := [2]int{4, 3}
+	connections := [][2]int{{1, 0}, {0, 1}, {1, 1}}
+	csr, err := CreateRegular2DCSRGrid(size, connections, gridCost)
+	assert.NoError(t, err)
+	graph, posToNode, err := CreateRegular2DGrid(size, connections, "default", 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, graph.Len(), csr.Len())
+	for pos, node := range posToNode {
+		idx := CSRGridIndex2D(size, pos)
+		assert.Equal(t, pos, CSRGridPos2D(size, idx))
+		assert.Equal(t, gridCost(pos), csr.Cost(idx))
+		neighbours := []*Node{}
+		for _, neigh := range csr.Neighbours(idx) {
+			neighbours = append(neighbours, posToNode[CSRGridPos2D(size, int(neigh))])
+		}
+		assert.ElementsMatch(t, node.connections, neighbours)
+	}
+
+	_, err = CreateRegular2DCSRGrid([2]int{-1, 1}, connections, gridCost)
+	assert.Error(t, err)
+	_, err = CreateRegular2DCSRGrid(size, connections, func([2]int) int { return -1 })
+	assert.Error(t, err)
+}
+
+func TestCSRSearcherFindPath(t *testing.T) {
+	size := [2]int{30, 20}
+	csr, err := CreateRegular2DCSRGrid(size, fourNeighbours, gridCost)
+	assert.NoError(t, err)
+	graph, posToNode, err := CreateRegular2DGrid(size, fourNeighbours, "heaped", 0)
+	assert.NoError(t, err)
+	for pos, node := range posToNode {
+		node.Cost = gridCost(pos)
+	}
+
+	searcher := NewCSRSearcher(csr)
+	queries := [][2][2]int{
+		{{0, 0}, {29, 19}},
+		{{29, 19}, {0, 0}},
+		{{10, 5}, {11, 5}},
+		{{3, 17}, {25, 2}},
+	}
+	for _, query := range queries {
+		endPos := query[1]
+		heuristic := func(idx int) int {
+			pos := CSRGridPos2D(size, idx)
+			return absInt(pos[0]-endPos[0]) + absInt(pos[1]-endPos[1])
+		}
+		path, cost, err := searcher.FindPath(
+			CSRGridIndex2D(size, query[0]), CSRGridIndex2D(size, endPos), heuristic,
+		)
+		assert.NoError(t, err)
+
+		nodePath, err := FindPath(graph, posToNode[query[0]], posToNode[endPos], zeroHeuristic)
+		assert.NoError(t, err)
+		expectedCost := NewPath(nodePath, nil).Cost
+		assert.Equal(t, expectedCost, cost)
+
+		// The path has to be connected and its cost has to match.
+		assert.Equal(t, CSRGridIndex2D(size, query[0]), path[0])
+		assert.Equal(t, CSRGridIndex2D(size, endPos), path[len(path)-1])
+		pathCost := 0
+		for idx := 1; idx < len(path); idx++ {
+			assert.Contains(t, csr.Neighbours(path[idx-1]), int32(path[idx]))
+			pathCost += csr.Cost(path[idx])
+		}
+		assert.Equal(t, expectedCost, pathCost)
+	}
+
+	path, cost, err := searcher.FindPath(5, 5, func(int) int { return 0 })
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5}, path)
+	assert.Zero(t, cost)
+}
+
+func TestCSRSearcherFindPathFailure(t *testing.T) {
+	// Two separate columns.
+	csr, err := CreateRegular2DCSRGrid([2]int{2, 3}, [][2]int{{0, 1}}, gridCost)
+	assert.NoError(t, err)
+	searcher := NewCSRSearcher(csr)
+	zero := func(int) int { return 0 }
+
+	_, _, err = searcher.FindPath(0, 5, zero)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, errNoPath))
+
+	_, _, err = searcher.FindPath(-1, 0, zero)
+	assert.Error(t, err)
+	_, _, err = searcher.FindPath(0, 6, zero)
+	assert.Error(t, err)
+
+	// The searcher can still be used afterwards.
+	path, _, err := searcher.FindPath(0, 2, zero)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, path)
+}
+
+func TestCSRSearcherStampOverflow(t *testing.T) {
+	csr, err := CreateRegular2DCSRGrid([2]int{5, 5}, fourNeighbours, gridCost)
+	assert.NoError(t, err)
+	searcher := NewCSRSearcher(csr)
+	zero := func(int) int { return 0 }
+
+	expected, expectedCost, err := searcher.FindPath(0, 24, zero)
+	assert.NoError(t, err)
+	// Most nodes are not reached by this search.
+	_, _, err = searcher.FindPath(0, 1, zero)
+	assert.NoError(t, err)
+	// Let the stamps wrap around. State left over from earlier searches must not be mistaken for
+	// state of the current one.
+	searcher.stamp = math.MaxUint32 - 6
+	for idx := 0; idx < 5; idx++ {
+		path, cost, err := searcher.FindPath(0, 24, zero)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, path)
+		assert.Equal(t, expectedCost, cost)
+	}
+}
@@ -0,0 +1,134 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	goheap "container/heap"
+)
+
+// The functions in this file compute exact costs between nodes without modifying the nodes. They
+// are used for preprocessing and validation, not for the actual path finding.
+
+// Type expandFn calls visit for every node that can be reached from node in a single step, together
+// with the cost of that step.
+type expandFn = func(node *Node, visit func(neigh *Node, cost int))
+
+// Function forwardExpand follows the connections of a node. The cost of a step is the cost of the
+// node stepped onto, just like for FindPath.
+func forwardExpand(node *Node, visit func(*Node, int)) {
+	for _, neigh := range node.connections {
+		visit(neigh, neigh.Cost)
+	}
+}
+
+// Function reverseExpand creates an expandFn that follows connections backwards, i.e. from a node
+// to all nodes in the graph that connect to it. Costs are those of the original connections. This
+// allows computing the costs for reaching a single node from all others.
+func reverseExpand(graph GraphOps) (expandFn, error) {
+	preds := map[*Node][]*Node{}
+	err := graph.Apply(func(node *Node) error {
+		for _, neigh := range node.connections {
+			preds[neigh] = append(preds[neigh], node)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	expand := func(node *Node, visit func(*Node, int)) {
+		for _, pred := range preds[node] {
+			visit(pred, node.Cost)
+		}
+	}
+	return expand, nil
+}
+
+// Function filteredExpand restricts another expandFn to nodes for which allowed returns true.
+func filteredExpand(expand expandFn, allowed func(*Node) bool) expandFn {
+	return func(node *Node, visit func(*Node, int)) {
+		expand(node, func(neigh *Node, cost int) {
+			if allowed(neigh) {
+				visit(neigh, cost)
+			}
+		})
+	}
+}
+
+type distEntry struct {
+	node *Node
+	dist int
+}
+
+type distHeap []distEntry
+
+func (h *distHeap) Len() int {
+	return len(*h)
+}
+
+func (h *distHeap) Less(i, j int) bool {
+	return (*h)[i].dist < (*h)[j].dist
+}
+
+func (h *distHeap) Swap(i, j int) {
+	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+}
+
+func (h *distHeap) Push(x interface{}) {
+	*h = append(*h, x.(distEntry))
+}
+
+func (h *distHeap) Pop() interface{} {
+	length := len(*h)
+	last := (*h)[length-1]
+	*h = (*h)[0 : length-1]
+	return last
+}
+
+// Function dijkstra determines the cheapest cost for reaching all nodes reachable from source via
+// expand. It returns those costs and the predecessor of each reached node on its cheapest way. If
+// target is not nil, the search stops as soon as the cost of target is known. In that case, the
+// costs for other nodes may not be final. Nodes that cannot be reached are not contained in the
+// result.
+func dijkstra(source *Node, expand expandFn, target *Node) (map[*Node]int, map[*Node]*Node) {
+	dist := map[*Node]int{source: 0}
+	prev := map[*Node]*Node{}
+	done := map[*Node]bool{}
+	open := distHeap{distEntry{node: source, dist: 0}}
+
+	for open.Len() != 0 {
+		current := goheap.Pop(&open).(distEntry)
+		// Nodes may be on the heap several times. Only the first, cheapest entry counts.
+		if done[current.node] {
+			continue
+		}
+		done[current.node] = true
+		if current.node == target {
+			break
+		}
+		expand(current.node, func(neigh *Node, cost int) {
+			newDist := current.dist + cost
+			if known, found := dist[neigh]; found && known <= newDist {
+				return
+			}
+			dist[neigh] = newDist
+			prev[neigh] = current.node
+			goheap.Push(&open, distEntry{node: neigh, dist: newDist})
+		})
+	}
+	return dist, prev
+}
@@ -0,0 +1,96 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Create a chain a->b->c with costs 1, 2, and 3. Also connect a directly to c.
+func setUpChain(t *testing.T) (GraphOps, *Node, *Node, *Node) {
+	nodeA, err := NewNode("a", 1, 0, nil)
+	assert.NoError(t, err)
+	nodeB, err := NewNode("b", 2, 0, nil)
+	assert.NoError(t, err)
+	nodeC, err := NewNode("c", 3, 0, nil)
+	assert.NoError(t, err)
+	nodeA.AddConnection(nodeB)
+	nodeB.AddConnection(nodeC)
+	graph := NewGraph(3)
+	graph.Add(nodeA)
+	graph.Add(nodeB)
+	graph.Add(nodeC)
+	return graph, nodeA, nodeB, nodeC
+}
+
+func TestDijkstraForward(t *testing.T) {
+	_, nodeA, nodeB, nodeC := setUpChain(t)
+
+	dist, prev := dijkstra(nodeA, forwardExpand, nil)
+
+	assert.Equal(t, map[*Node]int{nodeA: 0, nodeB: 2, nodeC: 5}, dist)
+	assert.Equal(t, map[*Node]*Node{nodeB: nodeA, nodeC: nodeB}, prev)
+
+	// A direct connection is cheaper.
+	nodeA.AddConnection(nodeC)
+	dist, prev = dijkstra(nodeA, forwardExpand, nil)
+	assert.Equal(t, 3, dist[nodeC])
+	assert.Equal(t, nodeA, prev[nodeC])
+
+	// Nothing can be reached from the end of the chain.
+	dist, _ = dijkstra(nodeC, forwardExpand, nil)
+	assert.Equal(t, map[*Node]int{nodeC: 0}, dist)
+}
+
+func TestDijkstraReverse(t *testing.T) {
+	graph, nodeA, nodeB, nodeC := setUpChain(t)
+
+	expand, err := reverseExpand(graph)
+	assert.NoError(t, err)
+	dist, _ := dijkstra(nodeC, expand, nil)
+
+	assert.Equal(t, map[*Node]int{nodeA: 5, nodeB: 3, nodeC: 0}, dist)
+}
+
+func TestDijkstraReverseApplyFailure(t *testing.T) {
+	_, err := reverseExpand(&failingApplyGraph{})
+	assert.Error(t, err)
+}
+
+func TestDijkstraFilteredAndTarget(t *testing.T) {
+	_, nodeA, nodeB, nodeC := setUpChain(t)
+
+	expand := filteredExpand(forwardExpand, func(node *Node) bool { return node != nodeC })
+	dist, _ := dijkstra(nodeA, expand, nil)
+	assert.Equal(t, map[*Node]int{nodeA: 0, nodeB: 2}, dist)
+
+	// The search stops at the target.
+	dist, _ = dijkstra(nodeA, forwardExpand, nodeB)
+	assert.Equal(t, 2, dist[nodeB])
+}
+
+// A graph whose Apply always fails. Everything else is taken from the mock graph ops.
+type failingApplyGraph struct {
+	mockGraphOps
+}
+
+func (g *failingApplyGraph) Apply(func(*Node) error) error {
+	return errMock
+}
@@ -0,0 +1,248 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// These are the colours used by WriteDOT. They are Graphviz colour names.
+const (
+	dotPathColour     = "red"
+	dotExpandedColour = "lightblue"
+	dotFrontierColour = "lightyellow"
+	dotPathPenWidth   = 3
+	// Nodes coloured by cost range from white for the cheapest to this shade of grey for the most
+	// expensive ones. The value is the brightness in the range [0, 255].
+	dotDarkestShade = 96
+	dotMaxShade     = 255
+)
+
+// DOTOption configures the output of WriteDOT. Obtain options via the functions starting with
+// "WithDOT", e.g. WithDOTPath.
+type DOTOption func(*dotOptions)
+
+// Type dotOptions collects the settings of all options passed to WriteDOT.
+type dotOptions struct {
+	path       []*Node
+	trace      *Trace
+	costColour bool
+	edgeLabel  func(from, to *Node) string
+}
+
+// WithDOTPath highlights a path, e.g. one found by FindPath. Nodes and connections on the path are
+// drawn in red.
+func WithDOTPath(path []*Node) DOTOption {
+	return func(o *dotOptions) {
+		o.path = path
+	}
+}
+
+// WithDOTTrace colours nodes by their state at the end of the search recorded in `trace`, see
+// WithTrace. Expanded nodes, i.e. those on the closed list, are drawn light blue. Nodes still on
+// the open list are drawn light yellow. The labels of these nodes also contain the tracked cost
+// and, for expanded nodes, when they were expanded.
+func WithDOTTrace(trace *Trace) DOTOption {
+	return func(o *dotOptions) {
+		o.trace = trace
+	}
+}
+
+// WithDOTCostColours fills nodes with a shade of grey depending on their cost, from white for the
+// cheapest to dark grey for the most expensive ones. Colours from WithDOTTrace take precedence.
+func WithDOTCostColours() DOTOption {
+	return func(o *dotOptions) {
+		o.costColour = true
+	}
+}
+
+// WithDOTEdgeLabels labels each connection with the string `label` returns for it. See
+// EdgeCostLabel for an example.
+func WithDOTEdgeLabels(label func(from, to *Node) string) DOTOption {
+	return func(o *dotOptions) {
+		o.edgeLabel = label
+	}
+}
+
+// EdgeCostLabel labels a connection with the cost of following it, which is the cost of the node
+// it leads to. Use it with WithDOTEdgeLabels.
+func EdgeCostLabel(_, to *Node) string {
+	return strconv.Itoa(to.Cost)
+}
+
+// Function dotQuote converts a string into a quoted DOT string. Line breaks are kept as such.
+func dotQuote(str string) string {
+	str = strings.ReplaceAll(str, `\`, `\\`)
+	str = strings.ReplaceAll(str, `"`, `\"`)
+	str = strings.ReplaceAll(str, "\n", `\n`)
+	return `"` + str + `"`
+}
+
+// Type dotStyle determines how to draw nodes and connections. It holds the settings of all options
+// in a form that allows quick lookups.
+type dotStyle struct {
+	dotOptions
+	pathNodes       map[*Node]bool
+	pathConnections map[[2]*Node]bool
+	// Member expanded holds, for each expanded node, when it was expanded, starting at one.
+	expanded         map[*Node]int
+	minCost, maxCost int
+}
+
+// Function newDOTStyle collects all information needed to draw the nodes of a graph.
+func newDOTStyle(options dotOptions, nodes []*Node) *dotStyle {
+	style := &dotStyle{
+		dotOptions:      options,
+		pathNodes:       map[*Node]bool{},
+		pathConnections: map[[2]*Node]bool{},
+		expanded:        map[*Node]int{},
+	}
+	for idx, node := range options.path {
+		style.pathNodes[node] = true
+		if idx > 0 {
+			style.pathConnections[[2]*Node{options.path[idx-1], node}] = true
+		}
+	}
+	if options.trace != nil {
+		for idx, node := range options.trace.Expanded {
+			style.expanded[node] = idx + 1
+		}
+	}
+	for idx, node := range nodes {
+		if idx == 0 || node.Cost < style.minCost {
+			style.minCost = node.Cost
+		}
+		if idx == 0 || node.Cost > style.maxCost {
+			style.maxCost = node.Cost
+		}
+	}
+	return style
+}
+
+// WriteDOT writes a graph to a writer in the DOT language understood by Graphviz, e.g. to look at
+// it via `dot -Tsvg`. Nodes are labelled with their IDs and costs. Connections to nodes outside the
+// graph are left out. Options can be provided to highlight a path, the state of a search, or node
+// costs, and to label connections.
+//
+// Nodes are sorted by their IDs. Since IDs need not be unique, nodes are named by their position in
+// that order in the output, e.g. n0 for the first one.
+func WriteDOT(writer io.Writer, graph GraphOps, opts ...DOTOption) error {
+	options := dotOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	nodes := make([]*Node, 0, graph.Len())
+	err := graph.Apply(func(node *Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sortNodesByID(nodes)
+	names := make(map[*Node]string, len(nodes))
+	for idx, node := range nodes {
+		names[node] = fmt.Sprintf("n%d", idx)
+	}
+	style := newDOTStyle(options, nodes)
+
+	lines := []string{"digraph astar {"}
+	for _, node := range nodes {
+		attrs := append([]string{"label=" + dotQuote(style.nodeLabel(node))}, style.node(node)...)
+		lines = append(lines, fmt.Sprintf("  %s [%s];", names[node], strings.Join(attrs, ", ")))
+	}
+	for _, node := range nodes {
+		for _, neigh := range node.connections {
+			if _, inGraph := names[neigh]; !inGraph {
+				continue
+			}
+			line := fmt.Sprintf("  %s -> %s", names[node], names[neigh])
+			if attrs := style.connection(node, neigh); len(attrs) > 0 {
+				line += fmt.Sprintf(" [%s]", strings.Join(attrs, ", "))
+			}
+			lines = append(lines, line+";")
+		}
+	}
+	lines = append(lines, "}")
+
+	_, err = io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// Function highlight provides the attributes for highlighting part of a path.
+func highlight() []string {
+	return []string{"color=" + dotPathColour, fmt.Sprintf("penwidth=%d", dotPathPenWidth)}
+}
+
+// Method nodeLabel determines the label of a node.
+func (s *dotStyle) nodeLabel(node *Node) string {
+	label := fmt.Sprintf("%s\ncost %d", node.ID, node.Cost)
+	if order, expanded := s.expanded[node]; expanded {
+		label += fmt.Sprintf("\nexpanded #%d", order)
+	}
+	if s.trace != nil {
+		if trackedCost, reached := s.trace.TrackedCosts[node]; reached {
+			label += fmt.Sprintf("\ntracked %d", trackedCost)
+		}
+	}
+	return label
+}
+
+// Method node determines the attributes describing how to draw a node, apart from its label.
+func (s *dotStyle) node(node *Node) []string {
+	attrs := []string{}
+	if s.pathNodes[node] {
+		attrs = append(attrs, highlight()...)
+	}
+	fill := ""
+	if s.costColour {
+		shade := dotMaxShade
+		if s.maxCost > s.minCost {
+			shade -= (node.Cost - s.minCost) * (dotMaxShade - dotDarkestShade) /
+				(s.maxCost - s.minCost)
+		}
+		fill = dotQuote(fmt.Sprintf("#%02x%02x%02x", shade, shade, shade))
+	}
+	if s.trace != nil {
+		if _, reached := s.trace.TrackedCosts[node]; reached {
+			fill = dotFrontierColour
+		}
+	}
+	if _, expanded := s.expanded[node]; expanded {
+		fill = dotExpandedColour
+	}
+	if fill != "" {
+		attrs = append(attrs, "style=filled", "fillcolor="+fill)
+	}
+	return attrs
+}
+
+// Method connection determines the attributes describing how to draw a connection.
+func (s *dotStyle) connection(from, to *Node) []string {
+	attrs := []string{}
+	if s.edgeLabel != nil {
+		attrs = append(attrs, "label="+dotQuote(s.edgeLabel(from, to)))
+	}
+	if s.pathConnections[[2]*Node{from, to}] {
+		attrs = append(attrs, highlight()...)
+	}
+	return attrs
+}
@@ -0,0 +1,109 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Set up a graph with two ways from s to e. The one via b is cheaper.
+func setUpDiamond(t *testing.T) (GraphOps, map[string]*Node) {
+	graph := NewHeapedGraph(0)
+	nodes := map[string]*Node{}
+	for _, id := range []string{"s", "a", "b", "e"} {
+		cost := map[string]int{"s": 0, "a": 5, "b": 1, "e": 1}[id]
+		node, err := NewNode(id, cost, 0, nil)
+		assert.NoError(t, err)
+		graph.Add(node)
+		nodes[id] = node
+	}
+	nodes["s"].AddPairwiseConnection(nodes["a"])
+	nodes["s"].AddPairwiseConnection(nodes["b"])
+	nodes["a"].AddPairwiseConnection(nodes["e"])
+	nodes["b"].AddPairwiseConnection(nodes["e"])
+	return graph, nodes
+}
+
+func TestWriteDOT(t *testing.T) {
+	graph, nodes := setUpDiamond(t)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+	nodes["a"].AddConnection(outside)
+	nodes["b"].ID = `"b"`
+
+	buffer := bytes.Buffer{}
+	assert.NoError(t, WriteDOT(&buffer, graph))
+	expected := `digraph astar {
+  n0 [label="\"b\"\ncost 1"];
+  n1 [label="a\ncost 5"];
+  n2 [label="e\ncost 1"];
+  n3 [label="s\ncost 0"];
+  n0 -> n3;
+  n0 -> n2;
+  n1 -> n3;
+  n1 -> n2;
+  n2 -> n1;
+  n2 -> n0;
+  n3 -> n1;
+  n3 -> n0;
+}
+`
+	assert.Equal(t, expected, buffer.String())
+
+	assert.Error(t, WriteDOT(&buffer, &failingApplyGraph{}))
+}
+
+func TestWriteDOTOptions(t *testing.T) {
+	graph, nodes := setUpDiamond(t)
+	trace := Trace{}
+	path, err := FindPath(graph, nodes["s"], nodes["e"], zeroHeuristic, WithTrace(&trace))
+	assert.NoError(t, err)
+
+	buffer := bytes.Buffer{}
+	err = WriteDOT(
+		&buffer, graph, WithDOTPath(path), WithDOTTrace(&trace), WithDOTCostColours(),
+		WithDOTEdgeLabels(EdgeCostLabel),
+	)
+	assert.NoError(t, err)
+	lines := strings.Split(buffer.String(), "\n")
+	// Node a is still on the open list, the others have been expanded.
+	assert.Equal(
+		t,
+		`  n0 [label="a\ncost 5\ntracked 5", style=filled, fillcolor=lightyellow];`,
+		lines[1],
+	)
+	assert.Equal(
+		t,
+		`  n1 [label="b\ncost 1\nexpanded #2\ntracked 1", color=red, penwidth=3, `+
+			`style=filled, fillcolor=lightblue];`,
+		lines[2],
+	)
+	assert.Contains(t, lines, `  n3 -> n1 [label="1", color=red, penwidth=3];`)
+	assert.Contains(t, lines, `  n3 -> n0 [label="5"];`)
+
+	// Nodes not reached by the search are coloured by cost.
+	buffer.Reset()
+	assert.NoError(t, WriteDOT(&buffer, graph, WithDOTCostColours()))
+	lines = strings.Split(buffer.String(), "\n")
+	assert.Equal(t, `  n0 [label="a\ncost 5", style=filled, fillcolor="#606060"];`, lines[1])
+	assert.Equal(t, `  n3 [label="s\ncost 0", style=filled, fillcolor="#ffffff"];`, lines[4])
+}
@@ -47,9 +47,21 @@ type GraphOps interface {
 	UpdateIfBetter(*Node, *Node, int)
 }
 
+// GraphFactory is implemented by graphs that can create empty graphs of their own kind. FindPath
+// uses it to obtain the open and closed lists for a search. Implement it for your own GraphOps to
+// be able to use them with FindPath. All graphs in this package implement it.
+type GraphFactory interface {
+	// NewEmpty creates an empty graph. The argument is the estimated number of nodes it will hold.
+	NewEmpty(sizeHint int) GraphOps
+}
+
 // Graph is a collection of nodes. Note that there are no guarantees for the nodes to be connected.
 // Ensuring that is the user's task. Each nodes is assigned to its estimate. That means a node's
 // estimate will never be able to change once added. Get a graph via NewGraph.
+//
+// Path finding with a Graph is deterministic: given the same nodes, created in the same order and
+// connected in the same order, the same path is found every time. Note that Apply still visits the
+// nodes in random order.
 type Graph map[*Node]int
 
 // NewGraph obtains a new graph. No arguments are required. This function returns a normal graph
@@ -60,6 +72,11 @@ func NewGraph(estimatedSize int) GraphOps {
 	return &self
 }
 
+// NewEmpty creates an empty graph of the same type. See GraphFactory.
+func (g *Graph) NewEmpty(sizeHint int) GraphOps {
+	return NewGraph(sizeHint)
+}
+
 // This is the default value for the graph. Specifying it once here simplifies the code.
 var graphVal = 0
 
@@ -95,16 +112,18 @@ func (g *Graph) Remove(node *Node) {
 }
 
 // PopCheapest retrieves one of the cheapest nodes and removes it. This will return nil if the graph
-// is empty.
+// is empty. Among equally cheap nodes, the one created first is chosen. That way, the result does
+// not depend on the random iteration order of Go's maps.
 func (g *Graph) PopCheapest() *Node {
 	found := false
 	cost := 0
 	var result *Node
 	for node, estimatedCost := range *g {
-		if !found || node.trackedCost+estimatedCost < cost {
+		nodeCost := node.trackedCost + estimatedCost
+		if !found || nodeCost < cost || (nodeCost == cost && node.serial < result.serial) {
 			found = true
 			result = node
-			cost = node.trackedCost + estimatedCost
+			cost = nodeCost
 		}
 	}
 	g.Remove(result)
@@ -129,11 +148,7 @@ func (g *Graph) UpdateIfBetter(node, prev *Node, newCost int) {
 	if !g.Has(node) {
 		panic(Error{"cannot update node outside this graph"})
 	}
-	newCost += node.Cost
-	if newCost < node.trackedCost {
-		node.prev = prev
-		node.trackedCost = newCost
-	}
+	node.UpdateIfBetter(prev, newCost)
 }
 
 // ToString provides a string representation of the graph. The nodes are sorted according to their
@@ -37,6 +37,18 @@ func TestNewGraph(t *testing.T) {
 	// assert.Equal(t, 100, cap(*graph))
 }
 
+func TestGraphNewEmpty(t *testing.T) {
+	for _, graph := range []GraphOps{NewGraph(0), NewHeapedGraph(0), NewBucketGraph(0)} {
+		node, err := NewNode("node", 0, 0, nil)
+		assert.NoError(t, err)
+		graph.Add(node)
+		empty := graph.(GraphFactory).NewEmpty(1)
+		assert.IsType(t, graph, empty)
+		assert.Zero(t, empty.Len())
+		assert.NotSame(t, graph, empty)
+	}
+}
+
 func TestGraphAddRemoveSuccess(t *testing.T) {
 	node, err := NewNode("node", 0, 0, nil)
 	assert.NoError(t, err)
@@ -128,6 +140,25 @@ func TestGraphPopCheapest(t *testing.T) {
 	assert.Equal(t, expectedCheapest, cheapest)
 }
 
+func TestGraphPopCheapestTies(t *testing.T) {
+	nodes := []*Node{}
+	for idx := 0; idx < 10; idx++ {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), 1, 0, nil)
+		assert.NoError(t, err)
+		nodes = append(nodes, node)
+	}
+	graph := Graph{}
+	// Add in reverse order to show that the order of creation matters.
+	for idx := len(nodes) - 1; idx >= 0; idx-- {
+		graph.Push(nodes[idx], 0)
+	}
+
+	// Equally cheap nodes are popped in the order they were created.
+	for _, node := range nodes {
+		assert.Equal(t, node, graph.PopCheapest())
+	}
+}
+
 func TestGraphToString(t *testing.T) {
 	graph := Graph{}
 	for idx, cost := range []int{1, 2, 0, 3} {
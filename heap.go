@@ -26,6 +26,8 @@ import (
 type HeapElement struct {
 	Node     *Node
 	Estimate int
+	// Member seq describes when the element was added. It is used to break ties.
+	seq int
 }
 
 // Heap is a collection of nodes on a minimum heap. It implements Go's heap.Interface. It is used by
@@ -45,16 +47,37 @@ func (h *Heap) Less(i, j int) bool {
 	return iElem.Node.trackedCost+iElem.Estimate < jElem.Node.trackedCost+jElem.Estimate
 }
 
-// Swap swaps two values in the heap. This is needed for Go's heap interface.
+// Swap swaps two values in the heap. This is needed for Go's heap interface. The nodes remember
+// their new positions.
 func (h *Heap) Swap(i, j int) {
 	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+	(*h)[i].Node.index = i
+	(*h)[j].Node.index = j
 }
 
 // Push adds a value to the heap. This is needed for Go's heap interface. Don't use it directly, use
 // Add to add nodes. This one will panic if you provide an incorrect type thanks to Go's lack of
 // generics.
 func (h *Heap) Push(x interface{}) {
-	*h = append(*h, x.(HeapElement))
+	elem := x.(HeapElement)
+	elem.Node.index = len(*h)
+	*h = append(*h, elem)
+}
+
+// Method position determines the position of a node in the heap. The position the node remembers
+// is used if it is correct. Otherwise, the heap is searched. If the node is not in the heap, -1 is
+// returned.
+func (h *Heap) position(node *Node) int {
+	if node.index >= 0 && node.index < len(*h) && (*h)[node.index].Node == node {
+		return node.index
+	}
+	for idx, elem := range *h {
+		if elem.Node == node {
+			node.index = idx
+			return idx
+		}
+	}
+	return -1
 }
 
 // Pop removes a value from the heap. This is needed for Go's heap interface.
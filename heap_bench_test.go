@@ -53,7 +53,7 @@ func BenchmarkHeapPush10KNodes(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for j := 0; j < tenK; j++ {
 			node, _ := NewNode("", tenK-j, 0, nil)
-			goheap.Push(&heap, HeapElement{node, 0})
+			goheap.Push(&heap, HeapElement{Node: node, Estimate: 0})
 		}
 	}
 }
@@ -64,7 +64,7 @@ func BenchmarkHeapPush100KNodes(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for j := 0; j < hundredK; j++ {
 			node, _ := NewNode("", hundredK-j, 0, nil)
-			goheap.Push(&heap, HeapElement{node, 0})
+			goheap.Push(&heap, HeapElement{Node: node, Estimate: 0})
 		}
 	}
 }
@@ -121,7 +121,7 @@ func BenchmarkHeapPop10KNodes(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for j := 0; j < tenK; j++ {
 			node, _ := NewNode("", 0, 0, nil)
-			goheap.Push(&heap, HeapElement{node, 0})
+			goheap.Push(&heap, HeapElement{Node: node, Estimate: 0})
 		}
 		for j := 0; j < tenK; j++ {
 			_ = goheap.Pop(&heap)
@@ -135,7 +135,7 @@ func BenchmarkHeapPop100KNodes(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for j := 0; j < hundredK; j++ {
 			node, _ := NewNode("", 0, 0, nil)
-			goheap.Push(&heap, HeapElement{node, 0})
+			goheap.Push(&heap, HeapElement{Node: node, Estimate: 0})
 		}
 		for j := 0; j < hundredK; j++ {
 			_ = goheap.Pop(&heap)
@@ -149,7 +149,7 @@ func BenchmarkHeapPopCheapest10KNodes(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for j := 0; j < tenK; j++ {
 			node, _ := NewNode("", 0, 0, nil)
-			goheap.Push(&heap, HeapElement{node, 0})
+			goheap.Push(&heap, HeapElement{Node: node, Estimate: 0})
 		}
 		for j := 0; j < tenK; j++ {
 			_ = goheap.Pop(&heap)
@@ -163,7 +163,7 @@ func BenchmarkHeapPopCheapest100KNodes(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for j := 0; j < hundredK; j++ {
 			node, _ := NewNode("", 0, 0, nil)
-			goheap.Push(&heap, HeapElement{node, 0})
+			goheap.Push(&heap, HeapElement{Node: node, Estimate: 0})
 		}
 		for j := 0; j < hundredK; j++ {
 			_ = goheap.Pop(&heap)
@@ -196,3 +196,49 @@ func BenchmarkHeapedGraphPopCheapest100KNodes(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkHeapedGraphUpdateIfBetter10KNodes(b *testing.B) {
+	graph := NewHeapedGraph(tenK)
+	nodes := make([]*Node, 0, tenK)
+	for j := 0; j < tenK; j++ {
+		node, _ := NewNode("", 0, 0, nil)
+		node.trackedCost = 2 * tenK * (b.N + 1)
+		graph.Add(node)
+		nodes = append(nodes, node)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, node := range nodes {
+			graph.UpdateIfBetter(node, nil, node.trackedCost-tenK-1)
+		}
+	}
+}
+
+func BenchmarkFindPathShortQuery100KNodes(b *testing.B) {
+	size := 316
+	graph, posToNode, _ := CreateRegular2DGrid([2]int{size, size}, fourNeighbours, "heaped", 1)
+	endPos := [2]int{size/2 + 2, size / 2}
+	start, end := posToNode[[2]int{size / 2, size / 2}], posToNode[endPos]
+	heuristic, _ := CreateConnectionsHeuristic2D(posToNode, endPos, fourNeighbours, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = FindPath(graph, start, end, heuristic)
+	}
+}
+
+func BenchmarkCSRSearcherShortQuery100KNodes(b *testing.B) {
+	size := [2]int{316, 316}
+	graph, _ := CreateRegular2DCSRGrid(size, fourNeighbours, func([2]int) int { return 1 })
+	searcher := NewCSRSearcher(graph)
+	endPos := [2]int{size[0]/2 + 2, size[1] / 2}
+	start := CSRGridIndex2D(size, [2]int{size[0] / 2, size[1] / 2})
+	end := CSRGridIndex2D(size, endPos)
+	heuristic := func(idx int) int {
+		pos := CSRGridPos2D(size, idx)
+		return absInt(pos[0]-endPos[0]) + absInt(pos[1]-endPos[1])
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = searcher.FindPath(start, end, heuristic)
+	}
+}
@@ -98,3 +98,34 @@ func TestHeapToString(t *testing.T) {
 		"{id: node5, cost: 5, con: ['']} -> 6"
 	assert.Equal(t, expectedString, heap.ToString(mockHeuristic))
 }
+
+func TestHeapPosition(t *testing.T) {
+	heap := Heap{}
+	goheap.Init(&heap)
+	nodes := []*Node{}
+	for _, cost := range []int{5, 2, 4, 6, 0, 4, 6, 2} {
+		node, err := NewNode(fmt.Sprint(cost), cost, 0, nil)
+		assert.NoError(t, err)
+		node.trackedCost = cost
+		goheap.Push(&heap, HeapElement{Node: node, Estimate: 0})
+		nodes = append(nodes, node)
+	}
+	goheap.Pop(&heap)
+
+	// Each node on the heap knows its position.
+	for idx, elem := range heap {
+		assert.Equal(t, idx, elem.Node.index)
+		assert.Equal(t, idx, heap.position(elem.Node))
+	}
+
+	// A wrong position is detected and corrected.
+	node := heap[3].Node
+	node.index = 1
+	assert.Equal(t, 3, heap.position(node))
+	assert.Equal(t, 3, node.index)
+	node.index = 100
+	assert.Equal(t, 3, heap.position(node))
+
+	// Nodes not on the heap are not found.
+	assert.Equal(t, -1, heap.position(nodes[4]))
+}
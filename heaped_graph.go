@@ -28,8 +28,17 @@ import (
 // connected. Ensuring that is the user's task. Each nodes is assigned to its estimate. That means a
 // node's estimate will never be able to change once added. Get a heaped graph via NewHeapedGraph.
 // This uses a Heap as storage backend.
+//
+// When used as open list, ties between nodes with the same total cost can be broken via a
+// TieBreaker. See SetTieBreaker.
 type HeapedGraph struct {
 	Heap Heap
+	// Member order breaks ties between nodes on the heap. It is nil if no tie-breaker is set.
+	order *tieBreakingHeap
+	// Member nextSeq is the sequence number for the next node added.
+	nextSeq int
+	// Member stamp identifies graphs used as search lists, see NewEmpty. It is zero otherwise.
+	stamp uint64
 }
 
 // NewHeapedGraph obtains a new heaped graph. Specify the estimated number of nodes as argument to
@@ -41,6 +50,15 @@ func NewHeapedGraph(estimatedSize int) GraphOps {
 	return self
 }
 
+// NewEmpty creates an empty heaped graph meant to be used as open or closed list by FindPath. The
+// tie-breaker is not copied. Such a search list keeps track of its nodes separately. Thus, a node
+// can be in one search list and one other heaped graph at the same time. See GraphFactory.
+func (g *HeapedGraph) NewEmpty(sizeHint int) GraphOps {
+	graph := NewHeapedGraph(sizeHint).(*HeapedGraph)
+	graph.stamp = nextStamp()
+	return graph
+}
+
 // Len determines the number of elements.
 func (g *HeapedGraph) Len() int {
 	return g.Heap.Len()
@@ -48,50 +66,64 @@ func (g *HeapedGraph) Len() int {
 
 // Has determines whether a graph contains a specific node.
 func (g *HeapedGraph) Has(node *Node) bool {
+	if g.stamp != 0 {
+		return node.list == g.stamp
+	}
 	return node.graph == g
 }
 
+// Method setMember marks a node as being in this graph or not.
+func (g *HeapedGraph) setMember(node *Node, member bool) {
+	switch {
+	case g.stamp != 0 && member:
+		node.list = g.stamp
+	case g.stamp != 0:
+		node.list = 0
+	case member:
+		node.graph = g
+	default:
+		node.graph = nil
+	}
+}
+
 // Add adds a node to the graph. If the node already exists, this a no-op. This panics if the node
 // already has a different graph set.
 func (g *HeapedGraph) Add(node *Node) {
 	if !g.Has(node) {
-		if node.graph != nil {
+		if g.stamp == 0 && node.graph != nil {
 			panic(Error{"different graph already set"})
 		}
-		elem := HeapElement{Node: node, Estimate: graphVal}
-		goheap.Push(&g.Heap, elem)
-		node.graph = g
+		g.Push(node, graphVal)
 	}
 }
 
 // Push adds a node to the graph, including its estimate. If the node already exists, this a no-op.
 func (g *HeapedGraph) Push(node *Node, estimate int) {
 	if !g.Has(node) {
-		elem := HeapElement{Node: node, Estimate: estimate}
-		goheap.Push(&g.Heap, elem)
-		node.graph = g
+		elem := HeapElement{Node: node, Estimate: estimate, seq: g.nextSeq}
+		g.nextSeq++
+		goheap.Push(g.ops(), elem)
+		g.setMember(node, true)
 	}
 }
 
-// Remove removes a node from the graph. If the node does not exist, this a no-op. This function is
-// inefficient but not needed for the algorithm in general.
+// Remove removes a node from the graph. If the node does not exist, this a no-op. This takes
+// logarithmic time in the number of nodes in the graph.
 func (g *HeapedGraph) Remove(findNode *Node) {
-	for idx, node := range g.Heap {
-		if node.Node == findNode {
-			g.Heap[idx].Node.graph = nil
-			g.Heap[idx].Node = nil
-			g.Heap = append(g.Heap[0:idx], g.Heap[idx+1:]...)
-			return
-		}
+	idx := g.Heap.position(findNode)
+	if idx < 0 {
+		return
 	}
+	elem := goheap.Remove(g.ops(), idx).(HeapElement)
+	g.setMember(elem.Node, false)
 }
 
 // PopCheapest retrieves one of the cheapest nodes and removes it. This will return nil if the graph
 // is empty.
 func (g *HeapedGraph) PopCheapest() *Node {
 	if len(g.Heap) > 0 {
-		val := goheap.Pop(&g.Heap).(HeapElement)
-		val.Node.graph = nil
+		val := goheap.Pop(g.ops()).(HeapElement)
+		g.setMember(val.Node, false)
 		return val.Node
 	}
 	return nil
@@ -115,21 +147,34 @@ func (g *HeapedGraph) UpdateIfBetter(node, prev *Node, newCost int) {
 	if !g.Has(node) {
 		panic(Error{"cannot update node outside this graph"})
 	}
-	newCost += node.Cost
-	if newCost < node.trackedCost {
-		node.prev = prev
-		node.trackedCost = newCost
-		// The node was updated, we need to fix the order in the heap. To do so, we need to find the
-		// node first and then call goheap.Fix on it. This can be very expensive.
-		for idx, elem := range g.Heap {
-			if elem.Node == node {
-				goheap.Fix(&g.Heap, idx)
-				break
-			}
+	if node.UpdateIfBetter(prev, newCost) {
+		// The node was updated, we need to fix the order in the heap. The node usually knows its
+		// position, which makes this take logarithmic time.
+		if idx := g.Heap.position(node); idx >= 0 {
+			goheap.Fix(g.ops(), idx)
 		}
 	}
 }
 
+// SetTieBreaker sets the tie-breaker used to order nodes with the same total cost. Nodes already in
+// the graph are re-ordered. Provide nil to break ties arbitrarily, which is the default.
+func (g *HeapedGraph) SetTieBreaker(tieBreaker TieBreaker) {
+	if tieBreaker == nil {
+		g.order = nil
+	} else {
+		g.order = &tieBreakingHeap{Heap: &g.Heap, tieBreaker: tieBreaker}
+	}
+	goheap.Init(g.ops())
+}
+
+// Method ops provides the heap interface to use for all heap operations.
+func (g *HeapedGraph) ops() goheap.Interface {
+	if g.order == nil {
+		return &g.Heap
+	}
+	return g.order
+}
+
 // ToString provides a string representation of the graph. The nodes are sorted according to their
 // user-defined names. If you provide a heuristic != nil, the value that heuristic provides for each
 // node is also provided at the end of a line. Providing nil will use the stored estimates.
@@ -207,3 +207,47 @@ func TestHeapedGraphUpdateIfBetterFailure(t *testing.T) {
 
 	graph.UpdateIfBetter(node, nil, 0)
 }
+
+func TestHeapedGraphRemoveKeepsOrder(t *testing.T) {
+	graph := NewHeapedGraph(0).(*HeapedGraph)
+	nodes := []*Node{}
+	for idx, cost := range []int{5, 2, 4, 6, 0, 4, 6, 2} {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), cost, 0, nil)
+		assert.NoError(t, err)
+		node.trackedCost = cost
+		graph.Push(node, 0)
+		nodes = append(nodes, node)
+	}
+
+	graph.Remove(nodes[4])
+	graph.Remove(nodes[1])
+	assert.Nil(t, nodes[4].graph)
+	assert.Equal(t, 6, graph.Len())
+
+	costs := []int{}
+	for graph.Len() > 0 {
+		costs = append(costs, graph.PopCheapest().trackedCost)
+	}
+	assert.Equal(t, []int{2, 4, 4, 5, 6, 6}, costs)
+}
+
+func TestHeapedGraphUpdateIfBetterStaleIndex(t *testing.T) {
+	graph := NewHeapedGraph(0).(*HeapedGraph)
+	otherGraph := NewHeapedGraph(0).(*HeapedGraph)
+	nodes := []*Node{}
+	for idx := 0; idx < 5; idx++ {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), 0, 0, nil)
+		assert.NoError(t, err)
+		node.trackedCost = 10 + idx
+		graph.Push(node, 0)
+		nodes = append(nodes, node)
+	}
+	// Adding the last node to another heap, e.g. while searching, changes its remembered position.
+	last := nodes[len(nodes)-1]
+	otherGraph.Heap.Push(HeapElement{Node: last})
+	assert.Equal(t, 0, last.index)
+
+	graph.UpdateIfBetter(last, nodes[0], 0)
+
+	assert.Equal(t, last, graph.PopCheapest())
+}
@@ -25,6 +25,11 @@ import "fmt"
 // costs. In many cases, the direct, line-of-sight distance is a good heuristic.
 type Heuristic = func(*Node) int
 
+// HeuristicFactory obtains a heuristic that estimates the remaining cost for reaching the given end
+// node. It is needed wherever several searches with different end nodes are run, e.g. when routing
+// via waypoints.
+type HeuristicFactory = func(end *Node) Heuristic
+
 // ConstantHeuristic can be used to construct a simple heuristic function with constant (as: never
 // changing for any one node, but differing between nodes) costs for reaching the end node. Use
 // AddNode to add a node with estimated cost and use Heuristic to retrieve the heuristic function.
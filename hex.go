@@ -0,0 +1,154 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+)
+
+// HexLayout determines how hexagons are oriented in a hex grid. This influences how positions in
+// a rectangular map, i.e. offset coordinates, relate to axial coordinates. See CreateHexGrid.
+type HexLayout int
+
+const (
+	// PointyTop hexagons have a corner at the top. They form horizontal rows. Odd rows are shifted
+	// right by half a hexagon.
+	PointyTop HexLayout = iota
+	// FlatTop hexagons have an edge at the top. They form vertical columns. Odd columns are shifted
+	// down by half a hexagon.
+	FlatTop
+)
+
+// These are the displacements from a hexagon to its six neighbours in axial coordinates. They are
+// the same for both layouts.
+var hexDirections = [][2]int{
+	[2]int{1, 0},
+	[2]int{1, -1},
+	[2]int{0, -1},
+	[2]int{-1, 0},
+	[2]int{-1, 1},
+	[2]int{0, 1},
+}
+
+// HexAxialToCube converts axial coordinates (q, r) into cube coordinates (q, r, s). The three cube
+// coordinates always sum up to zero.
+func HexAxialToCube(axial [2]int) [3]int {
+	return [3]int{axial[0], axial[1], -axial[0] - axial[1]}
+}
+
+// HexCubeToAxial converts cube coordinates (q, r, s) into axial coordinates (q, r). It is the
+// inverse of HexAxialToCube.
+func HexCubeToAxial(cube [3]int) [2]int {
+	return [2]int{cube[0], cube[1]}
+}
+
+// HexOffsetToAxial converts offset coordinates, i.e. the column and row of a hexagon in a
+// rectangular map, into axial coordinates. The layout determines which rows or columns are shifted,
+// see HexLayout. An unknown layout is treated like PointyTop.
+func HexOffsetToAxial(layout HexLayout, offset [2]int) [2]int {
+	col, row := offset[0], offset[1]
+	if layout == FlatTop {
+		return [2]int{col, row - (col-(col&1))/2}
+	}
+	return [2]int{col - (row-(row&1))/2, row}
+}
+
+// HexAxialToOffset converts axial coordinates into offset coordinates. It is the inverse of
+// HexOffsetToAxial.
+func HexAxialToOffset(layout HexLayout, axial [2]int) [2]int {
+	q, r := axial[0], axial[1]
+	if layout == FlatTop {
+		return [2]int{q, r + (q-(q&1))/2}
+	}
+	return [2]int{q + (r-(r&1))/2, r}
+}
+
+// HexDistance is the number of moves needed to get from one hexagon to another one, both given in
+// axial coordinates. It can be used as a Metric2D, e.g. for CreateScaledHeuristic2D.
+func HexDistance(pos1, pos2 [2]int) int {
+	qDist := pos1[0] - pos2[0]
+	rDist := pos1[1] - pos2[1]
+	return (absInt(qDist) + absInt(rDist) + absInt(qDist+rDist)) / 2
+}
+
+// CreateHexGrid creates a rectangular map of hexagons, each connected to its up to six neighbours,
+// in a graph suitable for path finding. All nodes have a cost of `defaultCost` assigned.
+//
+// Provide the number of columns and rows via the `size` argument and the orientation of the
+// hexagons via the `layout` argument. Also provide the name of the type of graph you want to
+// obtain, just like for CreateRegular2DGrid. Use HexOffsetToAxial to find the axial coordinates of
+// a hexagon in a given column and row. Node IDs look like "q:1,r:2".
+//
+// CreateHexGrid returns three values:
+// 1. A graph object suitable for path finding via FindPath.
+// 2. A map from axial coordinates to node pointers.
+// 3. An error value in case there were problems, e.g. if a dimension of `size` is negative.
+func CreateHexGrid(
+	size [2]int, layout HexLayout, graphType string, defaultCost int,
+) (GraphOps, map[[2]int]*Node, error) {
+	if layout != PointyTop && layout != FlatTop {
+		return nil, nil, fmt.Errorf("unknown hex layout, need PointyTop or FlatTop")
+	}
+	if size[0] < 0 || size[1] < 0 {
+		return nil, nil, fmt.Errorf("hex grid size must not be negative")
+	}
+	graph, err := newGraphOfType(graphType, size[0]*size[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	posToNode := map[[2]int]*Node{}
+	for col := 0; col < size[0]; col++ {
+		for row := 0; row < size[1]; row++ {
+			pos := HexOffsetToAxial(layout, [2]int{col, row})
+			nodeName := fmt.Sprintf("q:%d,r:%d", pos[0], pos[1])
+			node, err := NewNode(nodeName, defaultCost, len(hexDirections), nil)
+			if err != nil {
+				return nil, nil, err
+			}
+			posToNode[pos] = node
+			graph.Add(node)
+		}
+	}
+
+	for col := 0; col < size[0]; col++ {
+		for row := 0; row < size[1]; row++ {
+			pos := HexOffsetToAxial(layout, [2]int{col, row})
+			node := posToNode[pos]
+			for _, disp := range hexDirections {
+				neighPos := [2]int{pos[0] + disp[0], pos[1] + disp[1]}
+				if neigh, exists := posToNode[neighPos]; exists {
+					node.AddPairwiseConnection(neigh)
+				}
+			}
+		}
+	}
+
+	return graph, posToNode, nil
+}
+
+// CreateHexHeuristic creates a heuristic for a hex grid, e.g. one created via CreateHexGrid. It
+// takes a map from axial coordinates to node pointers and the desired end position. The estimate
+// for each node is the HexDistance to the destination multiplied by the minimum cost of all nodes
+// in `posMap`. Thus, the heuristic never over-estimates the actual costs. For nodes it does not
+// remember, it returns `defaultVal`. See CreateScaledHeuristic2D for details.
+func CreateHexHeuristic(
+	posMap map[[2]int]*Node, dest [2]int, defaultVal int,
+) (Heuristic, error) {
+	return CreateScaledHeuristic2D(posMap, dest, HexDistance, defaultVal)
+}
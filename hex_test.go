@@ -0,0 +1,111 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHexCoordinates(t *testing.T) {
+	assert.Equal(t, [3]int{2, -3, 1}, HexAxialToCube([2]int{2, -3}))
+	assert.Equal(t, [2]int{2, -3}, HexCubeToAxial([3]int{2, -3, 1}))
+
+	// Odd rows are shifted right for pointy-top hexagons.
+	assert.Equal(t, [2]int{0, 1}, HexOffsetToAxial(PointyTop, [2]int{0, 1}))
+	assert.Equal(t, [2]int{-1, 2}, HexOffsetToAxial(PointyTop, [2]int{0, 2}))
+	assert.Equal(t, [2]int{1, -1}, HexOffsetToAxial(PointyTop, [2]int{0, -1}))
+	// Odd columns are shifted down for flat-top hexagons.
+	assert.Equal(t, [2]int{1, 0}, HexOffsetToAxial(FlatTop, [2]int{1, 0}))
+	assert.Equal(t, [2]int{2, -1}, HexOffsetToAxial(FlatTop, [2]int{2, 0}))
+	assert.Equal(t, [2]int{-1, 1}, HexOffsetToAxial(FlatTop, [2]int{-1, 0}))
+
+	for _, layout := range []HexLayout{PointyTop, FlatTop} {
+		for col := -3; col <= 3; col++ {
+			for row := -3; row <= 3; row++ {
+				offset := [2]int{col, row}
+				assert.Equal(t, offset, HexAxialToOffset(layout, HexOffsetToAxial(layout, offset)))
+			}
+		}
+	}
+}
+
+func TestHexDistance(t *testing.T) {
+	assert.Equal(t, 0, HexDistance([2]int{1, 1}, [2]int{1, 1}))
+	for _, disp := range hexDirections {
+		assert.Equal(t, 1, HexDistance([2]int{0, 0}, disp))
+	}
+	assert.Equal(t, 3, HexDistance([2]int{0, 0}, [2]int{3, -3}))
+	assert.Equal(t, 6, HexDistance([2]int{0, 0}, [2]int{3, 3}))
+	assert.Equal(t, 4, HexDistance([2]int{-1, 2}, [2]int{2, -2}))
+}
+
+func TestCreateHexGrid(t *testing.T) {
+	for _, layout := range []HexLayout{PointyTop, FlatTop} {
+		for _, graphType := range []string{"default", "heaped", "bucket"} {
+			graph, posToNode, err := CreateHexGrid([2]int{3, 3}, layout, graphType, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, 9, graph.Len())
+			assert.Equal(t, 9, len(posToNode))
+
+			center := posToNode[HexOffsetToAxial(layout, [2]int{1, 1})]
+			assert.Equal(t, 6, len(center.connections))
+			corner := posToNode[HexOffsetToAxial(layout, [2]int{0, 0})]
+			assert.Equal(t, 2, len(corner.connections))
+			for _, neigh := range center.connections {
+				assert.True(t, neigh.connectedTo(center))
+			}
+		}
+	}
+	_, posToNode, err := CreateHexGrid([2]int{1, 2}, PointyTop, "heaped", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "q:0,r:1", posToNode[[2]int{0, 1}].ID)
+
+	_, _, err = CreateHexGrid([2]int{3, 3}, HexLayout(2), "heaped", 1)
+	assert.Error(t, err)
+	_, _, err = CreateHexGrid([2]int{3, 3}, PointyTop, "unknownType", 1)
+	assert.Error(t, err)
+	_, _, err = CreateHexGrid([2]int{3, 3}, PointyTop, "heaped", -1)
+	assert.Error(t, err)
+	_, _, err = CreateHexGrid([2]int{-3, -3}, PointyTop, "heaped", 1)
+	assert.Error(t, err)
+	_, _, err = CreateHexGrid([2]int{3, -1}, FlatTop, "heaped", 1)
+	assert.Error(t, err)
+}
+
+func TestCreateHexHeuristic(t *testing.T) {
+	for _, layout := range []HexLayout{PointyTop, FlatTop} {
+		graph, posToNode, err := CreateHexGrid([2]int{8, 6}, layout, "heaped", 2)
+		assert.NoError(t, err)
+		start := HexOffsetToAxial(layout, [2]int{0, 0})
+		end := HexOffsetToAxial(layout, [2]int{7, 5})
+
+		heuristic, err := CreateHexHeuristic(posToNode, end, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 2*HexDistance(start, end), heuristic(posToNode[start]))
+
+		path, err := FindPath(graph, posToNode[start], posToNode[end], heuristic)
+		assert.NoError(t, err)
+		assert.Equal(t, HexDistance(start, end)+1, len(path))
+		// Each step goes to a neighbouring hexagon.
+		for idx := 1; idx < len(path); idx++ {
+			assert.True(t, path[idx-1].connectedTo(path[idx]))
+		}
+	}
+}
@@ -0,0 +1,468 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A hpaTransition is a connection chosen for crossing from one cluster into a neighbouring one.
+type hpaTransition struct {
+	from *Node
+	to   *Node
+}
+
+// Type hpaCluster holds the abstract information about a single cluster.
+type hpaCluster struct {
+	// Member entrances holds all nodes of the cluster that are part of a transition, sorted by ID.
+	entrances []*Node
+	// Member costs holds the cost of the cheapest path between two entrances that stays inside the
+	// cluster. Entrances that cannot reach each other that way are not contained.
+	costs map[*Node]map[*Node]int
+}
+
+// HierarchicalGrid provides hierarchical path finding (HPA*) for large 2D grids such as those
+// created by CreateRegular2DGrid. The grid is split into square clusters. Where connections cross
+// from one cluster into a neighbouring one, a few of them are chosen as transitions: one per
+// contiguous run of crossing connections. Their nodes, the entrances, form an abstract graph
+// together with the cheapest paths between entrances of the same cluster, which are precomputed.
+//
+// A query first plans on the small abstract graph, which is very fast even for huge grids. The
+// resulting rough path can be refined leg by leg, each leg requiring only a search inside a single
+// cluster. Paths found this way are usually close to but not guaranteed to be the cheapest ones.
+// Obtain one via NewHierarchicalGrid.
+//
+// When costs or connections change, call Update with the affected positions. Only the affected
+// clusters and, if their entrances change, their neighbours are rebuilt. No nodes are modified,
+// neither during preprocessing nor during queries.
+type HierarchicalGrid struct {
+	clusterSize int
+	posToNode   map[[2]int]*Node
+	nodeToPos   map[*Node][2]int
+	clusters    map[[2]int]*hpaCluster
+	// Member transitions holds, for each ordered pair of neighbouring clusters, the transitions
+	// from the first into the second.
+	transitions map[[2][2]int][]hpaTransition
+}
+
+// NewHierarchicalGrid preprocesses a grid for hierarchical path finding. It takes a map from node
+// positions to node pointers as returned by CreateRegular2DGrid and the edge length of the square
+// clusters. Connections to nodes not in the map are ignored. Connections may only lead to nodes in
+// the same or in a neighbouring cluster, which is always the case if the displacements of the
+// connections do not exceed the cluster size.
+//
+// Larger clusters mean a smaller abstract graph but more expensive refinement. For big grids, a
+// cluster size between 16 and 64 is a good starting point.
+func NewHierarchicalGrid(posToNode map[[2]int]*Node, clusterSize int) (*HierarchicalGrid, error) {
+	if clusterSize <= 0 {
+		return nil, fmt.Errorf("input sanitation: cluster size must be positive")
+	}
+	nodeToPos, err := invertPosMap2D(posToNode)
+	if err != nil {
+		return nil, err
+	}
+	grid := &HierarchicalGrid{
+		clusterSize: clusterSize,
+		posToNode:   posToNode,
+		nodeToPos:   nodeToPos,
+		clusters:    map[[2]int]*hpaCluster{},
+		transitions: map[[2][2]int][]hpaTransition{},
+	}
+	for pos := range posToNode {
+		grid.clusters[grid.clusterOf(pos)] = nil
+	}
+	for cluster := range grid.clusters {
+		if err := grid.buildTransitions(cluster); err != nil {
+			return nil, err
+		}
+	}
+	for cluster := range grid.clusters {
+		grid.buildCluster(cluster)
+	}
+	return grid, nil
+}
+
+// Function floorDiv divides rounding towards negative infinity.
+func floorDiv(val, div int) int {
+	if val < 0 {
+		return -((-val + div - 1) / div)
+	}
+	return val / div
+}
+
+// Method clusterOf determines the cluster a position belongs to.
+func (g *HierarchicalGrid) clusterOf(pos [2]int) [2]int {
+	return [2]int{floorDiv(pos[0], g.clusterSize), floorDiv(pos[1], g.clusterSize)}
+}
+
+// Function neighbourClusters lists the keys of all clusters surrounding the given one, no matter
+// whether they exist.
+func neighbourClusters(cluster [2]int) [][2]int {
+	result := [][2]int{}
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if dx != 0 || dy != 0 {
+				result = append(result, [2]int{cluster[0] + dx, cluster[1] + dy})
+			}
+		}
+	}
+	return result
+}
+
+// Method clusterNodes lists all nodes in a cluster.
+func (g *HierarchicalGrid) clusterNodes(cluster [2]int) []*Node {
+	result := []*Node{}
+	for x := cluster[0] * g.clusterSize; x < (cluster[0]+1)*g.clusterSize; x++ {
+		for y := cluster[1] * g.clusterSize; y < (cluster[1]+1)*g.clusterSize; y++ {
+			if node, found := g.posToNode[[2]int{x, y}]; found {
+				result = append(result, node)
+			}
+		}
+	}
+	return result
+}
+
+// Method inCluster creates a function that determines whether a node belongs to a cluster.
+func (g *HierarchicalGrid) inCluster(cluster [2]int) func(*Node) bool {
+	return func(node *Node) bool {
+		pos, found := g.nodeToPos[node]
+		return found && g.clusterOf(pos) == cluster
+	}
+}
+
+// Method buildTransitions determines the transitions leaving a cluster. Crossing connections into
+// the same neighbouring cluster form a run as long as both their start nodes and their end nodes
+// are linked. The middle connection of each run is chosen as transition. Since each node of a run
+// can reach the others without leaving its cluster, dropping the other connections of a run never
+// makes a node unreachable. That is not the case for merely adjacent nodes, e.g. if a wall splits
+// a cluster.
+func (g *HierarchicalGrid) buildTransitions(cluster [2]int) error {
+	crossings := map[[2]int][]hpaTransition{}
+	for _, node := range g.clusterNodes(cluster) {
+		for _, neigh := range node.connections {
+			neighPos, found := g.nodeToPos[neigh]
+			if !found {
+				continue
+			}
+			neighCluster := g.clusterOf(neighPos)
+			if neighCluster == cluster {
+				continue
+			}
+			if absInt(neighCluster[0]-cluster[0]) > 1 || absInt(neighCluster[1]-cluster[1]) > 1 {
+				return fmt.Errorf(
+					"input sanitation: connection from %s to %s skips clusters", node.ID, neigh.ID,
+				)
+			}
+			crossings[neighCluster] = append(
+				crossings[neighCluster], hpaTransition{from: node, to: neigh},
+			)
+		}
+	}
+
+	for _, neighCluster := range neighbourClusters(cluster) {
+		key := [2][2]int{cluster, neighCluster}
+		delete(g.transitions, key)
+		candidates := crossings[neighCluster]
+		if len(candidates) == 0 {
+			continue
+		}
+		g.sortTransitions(candidates)
+		runStart := 0
+		for idx := 1; idx <= len(candidates); idx++ {
+			if idx < len(candidates) && linked(candidates[idx-1], candidates[idx]) {
+				continue
+			}
+			g.transitions[key] = append(g.transitions[key], candidates[(runStart+idx-1)/2])
+			runStart = idx
+		}
+	}
+	return nil
+}
+
+// Method sortTransitions sorts transitions by the positions of their nodes.
+func (g *HierarchicalGrid) sortTransitions(transitions []hpaTransition) {
+	less := func(pos1, pos2 [2]int) bool {
+		if pos1[0] == pos2[0] {
+			return pos1[1] < pos2[1]
+		}
+		return pos1[0] < pos2[0]
+	}
+	sort.Slice(transitions, func(idx1, idx2 int) bool {
+		from1, from2 := g.nodeToPos[transitions[idx1].from], g.nodeToPos[transitions[idx2].from]
+		if from1 == from2 {
+			return less(g.nodeToPos[transitions[idx1].to], g.nodeToPos[transitions[idx2].to])
+		}
+		return less(from1, from2)
+	})
+}
+
+// Function linked determines whether two crossing connections belong to the same run. That is the
+// case if their start nodes are the same or connected in both directions and the same holds for
+// their end nodes.
+func linked(trans1, trans2 hpaTransition) bool {
+	mutual := func(node1, node2 *Node) bool {
+		return node1 == node2 || node1.connectedTo(node2) && node2.connectedTo(node1)
+	}
+	return mutual(trans1.from, trans2.from) && mutual(trans1.to, trans2.to)
+}
+
+// Method entrances determines the entrances of a cluster from the transitions leaving and entering
+// it.
+func (g *HierarchicalGrid) entrances(cluster [2]int) []*Node {
+	seen := map[*Node]bool{}
+	result := []*Node{}
+	add := func(node *Node) {
+		if !seen[node] {
+			seen[node] = true
+			result = append(result, node)
+		}
+	}
+	for _, neighCluster := range neighbourClusters(cluster) {
+		for _, trans := range g.transitions[[2][2]int{cluster, neighCluster}] {
+			add(trans.from)
+		}
+		for _, trans := range g.transitions[[2][2]int{neighCluster, cluster}] {
+			add(trans.to)
+		}
+	}
+	sortNodesByID(result)
+	return result
+}
+
+// Method buildCluster determines the entrances of a cluster and the costs between them.
+func (g *HierarchicalGrid) buildCluster(cluster [2]int) {
+	entrances := g.entrances(cluster)
+	expand := filteredExpand(forwardExpand, g.inCluster(cluster))
+	costs := make(map[*Node]map[*Node]int, len(entrances))
+	for _, from := range entrances {
+		dist, _ := dijkstra(from, expand, nil)
+		costs[from] = map[*Node]int{}
+		for _, to := range entrances {
+			if cost, found := dist[to]; found && to != from {
+				costs[from][to] = cost
+			}
+		}
+	}
+	g.clusters[cluster] = &hpaCluster{entrances: entrances, costs: costs}
+}
+
+// Method clusterReverseExpand creates an expandFn that follows connections backwards inside a
+// cluster.
+func (g *HierarchicalGrid) clusterReverseExpand(cluster [2]int) expandFn {
+	preds := map[*Node][]*Node{}
+	inCluster := g.inCluster(cluster)
+	for _, node := range g.clusterNodes(cluster) {
+		for _, neigh := range node.connections {
+			if inCluster(neigh) {
+				preds[neigh] = append(preds[neigh], node)
+			}
+		}
+	}
+	return func(node *Node, visit func(*Node, int)) {
+		for _, pred := range preds[node] {
+			visit(pred, node.Cost)
+		}
+	}
+}
+
+// FindAbstractPath plans a rough path between two nodes of the grid on the abstract graph. The
+// result starts with the start node, ends with the end node, and contains the entrances passed on
+// the way in between. Consecutive nodes are either connected directly or belong to the same
+// cluster. Use Refine to obtain the full path. If start and end are the same, the path contains
+// only that node.
+func (g *HierarchicalGrid) FindAbstractPath(start, end *Node) ([]*Node, error) {
+	startPos, found := g.nodeToPos[start]
+	if !found {
+		return []*Node{}, fmt.Errorf("input sanitation: start node not in grid")
+	}
+	endPos, found := g.nodeToPos[end]
+	if !found {
+		return []*Node{}, fmt.Errorf("input sanitation: end node not in grid")
+	}
+	if start == end {
+		return []*Node{start}, nil
+	}
+	startCluster, endCluster := g.clusterOf(startPos), g.clusterOf(endPos)
+
+	// Temporarily connect the start node to the entrances of its cluster and those of the end
+	// node's cluster to the end node. If both are in the same cluster, also connect them directly.
+	startDist, _ := dijkstra(start, filteredExpand(forwardExpand, g.inCluster(startCluster)), nil)
+	startEdges := map[*Node]int{}
+	for _, entrance := range g.clusters[startCluster].entrances {
+		if cost, found := startDist[entrance]; found {
+			startEdges[entrance] = cost
+		}
+	}
+	if cost, found := startDist[end]; found {
+		startEdges[end] = cost
+	}
+	endDist, _ := dijkstra(end, g.clusterReverseExpand(endCluster), nil)
+	endEdges := map[*Node]int{}
+	for _, entrance := range g.clusters[endCluster].entrances {
+		if cost, found := endDist[entrance]; found {
+			endEdges[entrance] = cost
+		}
+	}
+
+	_, prev := dijkstra(start, g.abstractExpand(start, end, startEdges, endEdges), end)
+	return prevPath(prev, start, end)
+}
+
+// Method abstractExpand creates an expandFn for the abstract graph. It follows the precomputed
+// connections between entrances as well as the temporary ones from the start node and to the end
+// node.
+func (g *HierarchicalGrid) abstractExpand(
+	start, end *Node, startEdges, endEdges map[*Node]int,
+) expandFn {
+	return func(node *Node, visit func(*Node, int)) {
+		if node == start {
+			for to, cost := range startEdges {
+				visit(to, cost)
+			}
+		}
+		if cost, found := endEdges[node]; found {
+			visit(end, cost)
+		}
+		cluster := g.clusterOf(g.nodeToPos[node])
+		for to, cost := range g.clusters[cluster].costs[node] {
+			visit(to, cost)
+		}
+		for _, neighCluster := range neighbourClusters(cluster) {
+			for _, trans := range g.transitions[[2][2]int{cluster, neighCluster}] {
+				if trans.from == node {
+					visit(trans.to, trans.to.Cost)
+				}
+			}
+		}
+	}
+}
+
+// Function prevPath extracts the path from start to end from the predecessors determined by
+// dijkstra. The path is returned in the correct order.
+func prevPath(prev map[*Node]*Node, start, end *Node) ([]*Node, error) {
+	if _, found := prev[end]; !found && start != end {
+		return []*Node{}, fmt.Errorf("no path found: %w", errNoPath)
+	}
+	path := []*Node{}
+	for node := end; node != start; node = prev[node] {
+		path = append(path, node)
+	}
+	path = append(path, start)
+	for left, right := 0, len(path)-1; left < right; left, right = left+1, right-1 {
+		path[left], path[right] = path[right], path[left]
+	}
+	return path, nil
+}
+
+// Refine turns a rough path as returned by FindAbstractPath into a full path. Consecutive nodes
+// belonging to the same cluster are joined by the cheapest path inside that cluster. Refining is
+// cheap and can be done leg by leg, e.g. by passing only the first few nodes of a rough path. An
+// error is returned if the grid changed in a way that makes the rough path impossible to follow.
+func (g *HierarchicalGrid) Refine(abstractPath []*Node) ([]*Node, error) {
+	if len(abstractPath) == 0 {
+		return []*Node{}, fmt.Errorf("input sanitation: empty path")
+	}
+	legs := [][]*Node{{abstractPath[0]}}
+	for idx := 1; idx < len(abstractPath); idx++ {
+		from, to := abstractPath[idx-1], abstractPath[idx]
+		fromPos, fromFound := g.nodeToPos[from]
+		toPos, toFound := g.nodeToPos[to]
+		if !fromFound || !toFound {
+			return []*Node{}, fmt.Errorf("input sanitation: node not in grid")
+		}
+		if cluster := g.clusterOf(fromPos); cluster == g.clusterOf(toPos) {
+			_, prev := dijkstra(from, filteredExpand(forwardExpand, g.inCluster(cluster)), to)
+			leg, err := prevPath(prev, from, to)
+			if err != nil {
+				return []*Node{}, err
+			}
+			legs = append(legs, leg)
+		} else if from.connectedTo(to) {
+			legs = append(legs, []*Node{from, to})
+		} else {
+			return []*Node{}, fmt.Errorf("no path found: %w", errNoPath)
+		}
+	}
+	return joinLegs(legs), nil
+}
+
+// FindPath finds a full path between two nodes of the grid. It is the same as refining the result
+// of FindAbstractPath completely.
+func (g *HierarchicalGrid) FindPath(start, end *Node) ([]*Node, error) {
+	abstractPath, err := g.FindAbstractPath(start, end)
+	if err != nil {
+		return []*Node{}, err
+	}
+	return g.Refine(abstractPath)
+}
+
+// Update rebuilds the abstract information for all clusters containing one of the given positions.
+// Call it after changing the costs or connections of nodes at those positions. Neighbouring
+// clusters are rebuilt only if their entrances change.
+func (g *HierarchicalGrid) Update(positions ...[2]int) error {
+	affected := map[[2]int]bool{}
+	for _, pos := range positions {
+		if _, found := g.posToNode[pos]; !found {
+			return fmt.Errorf("input sanitation: position %v not in grid", pos)
+		}
+		affected[g.clusterOf(pos)] = true
+	}
+
+	// Transitions into an affected cluster are determined by the neighbouring clusters.
+	rebuildTransitions := map[[2]int]bool{}
+	for cluster := range affected {
+		rebuildTransitions[cluster] = true
+		for _, neighCluster := range neighbourClusters(cluster) {
+			if _, exists := g.clusters[neighCluster]; exists {
+				rebuildTransitions[neighCluster] = true
+			}
+		}
+	}
+	for cluster := range rebuildTransitions {
+		if err := g.buildTransitions(cluster); err != nil {
+			return err
+		}
+	}
+
+	for cluster := range affected {
+		g.buildCluster(cluster)
+	}
+	for cluster := range rebuildTransitions {
+		if affected[cluster] {
+			continue
+		}
+		if entrances := g.entrances(cluster); !sameNodes(entrances, g.clusters[cluster].entrances) {
+			g.buildCluster(cluster)
+		}
+	}
+	return nil
+}
+
+// Function sameNodes determines whether two slices contain the same nodes in the same order.
+func sameNodes(nodes1, nodes2 []*Node) bool {
+	if len(nodes1) != len(nodes2) {
+		return false
+	}
+	for idx := range nodes1 {
+		if nodes1[idx] != nodes2[idx] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,222 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Remove all connections between two nodes.
+func disconnect(node1, node2 *Node) {
+	node1.RemoveConnection(node2)
+	node2.RemoveConnection(node1)
+}
+
+func TestHierarchicalGridFindPath(t *testing.T) {
+	graph, posToNode := setUpVaryingGrid(t, 12, "heaped")
+
+	grid, err := NewHierarchicalGrid(posToNode, 4)
+	assert.NoError(t, err)
+
+	for _, positions := range [][2][2]int{
+		{{0, 0}, {11, 11}},
+		{{11, 0}, {0, 7}},
+		{{5, 5}, {6, 6}},
+		// Both in the same cluster.
+		{{1, 1}, {3, 2}},
+	} {
+		start, end := posToNode[positions[0]], posToNode[positions[1]]
+
+		abstractPath, err := grid.FindAbstractPath(start, end)
+		assert.NoError(t, err)
+		assert.Equal(t, start, abstractPath[0])
+		assert.Equal(t, end, abstractPath[len(abstractPath)-1])
+
+		path, err := grid.FindPath(start, end)
+		assert.NoError(t, err)
+		assert.Equal(t, start, path[0])
+		assert.Equal(t, end, path[len(path)-1])
+		assertPathConnected(t, path)
+
+		// The path is close to but not necessarily as cheap as the cheapest one.
+		reference, err := FindPath(graph, start, end, zeroHeuristic)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, NewPath(path, nil).Cost, NewPath(reference, nil).Cost)
+		assert.LessOrEqual(t, NewPath(path, nil).Cost, 2*NewPath(reference, nil).Cost)
+	}
+
+	// Within a cluster, the cheapest path is found if it does not leave the cluster.
+	path, err := grid.FindPath(posToNode[[2]int{1, 1}], posToNode[[2]int{3, 2}])
+	assert.NoError(t, err)
+	assert.Equal(t, 13, NewPath(path, nil).Cost)
+
+	path, err = grid.FindPath(posToNode[[2]int{2, 2}], posToNode[[2]int{2, 2}])
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{posToNode[[2]int{2, 2}]}, path)
+}
+
+func TestHierarchicalGridFindPathDiagonal(t *testing.T) {
+	connections := append([][2]int{[2]int{1, 1}, [2]int{1, -1}}, fourNeighbours...)
+	graph, posToNode, err := CreateRegular2DGrid([2]int{8, 8}, connections, "heaped", 1)
+	assert.NoError(t, err)
+	// A wall splits the cluster at the bottom right into two parts. Crossing connections into the
+	// left part and into the right part start at adjacent nodes but must not be merged.
+	for y := 4; y < 8; y++ {
+		isolate(posToNode[[2]int{6, y}])
+	}
+	grid, err := NewHierarchicalGrid(posToNode, 4)
+	assert.NoError(t, err)
+
+	for _, positions := range [][2][2]int{
+		{{7, 3}, {7, 4}},
+		{{6, 3}, {7, 4}},
+		{{0, 0}, {7, 7}},
+		{{4, 3}, {5, 4}},
+	} {
+		start, end := posToNode[positions[0]], posToNode[positions[1]]
+		path, err := grid.FindPath(start, end)
+		assert.NoError(t, err, positions)
+		assertPathConnected(t, path)
+		assert.Equal(t, end, path[len(path)-1])
+		// Paths may take small detours, but every reachable pair has to be found.
+		_, err = FindPath(graph, start, end, zeroHeuristic)
+		assert.NoError(t, err)
+	}
+}
+
+func TestHierarchicalGridRefineLegs(t *testing.T) {
+	_, posToNode, err := CreateRegular2DGrid([2]int{8, 8}, fourNeighbours, "default", 1)
+	assert.NoError(t, err)
+	grid, err := NewHierarchicalGrid(posToNode, 4)
+	assert.NoError(t, err)
+
+	start, end := posToNode[[2]int{0, 0}], posToNode[[2]int{7, 7}]
+	abstractPath, err := grid.FindAbstractPath(start, end)
+	assert.NoError(t, err)
+	// The path passes at least two borders, each with one entrance on either side.
+	assert.GreaterOrEqual(t, len(abstractPath), 6)
+
+	// Refining leg by leg yields a path as cheap as refining everything at once.
+	full, err := grid.Refine(abstractPath)
+	assert.NoError(t, err)
+	assertPathConnected(t, full)
+	joined := []*Node{start}
+	for idx := 1; idx < len(abstractPath); idx++ {
+		leg, err := grid.Refine(abstractPath[idx-1 : idx+1])
+		assert.NoError(t, err)
+		assert.Equal(t, abstractPath[idx-1], leg[0])
+		assert.Equal(t, abstractPath[idx], leg[len(leg)-1])
+		joined = append(joined, leg[1:]...)
+	}
+	assertPathConnected(t, joined)
+	assert.Equal(t, NewPath(full, nil).Cost, NewPath(joined, nil).Cost)
+	// On a grid with uniform costs, going around is not needed.
+	assert.Equal(t, 14, NewPath(full, nil).Cost)
+}
+
+func TestHierarchicalGridUpdate(t *testing.T) {
+	// Two clusters next to each other.
+	_, posToNode, err := CreateRegular2DGrid([2]int{8, 4}, fourNeighbours, "default", 1)
+	assert.NoError(t, err)
+	grid, err := NewHierarchicalGrid(posToNode, 4)
+	assert.NoError(t, err)
+	start, end := posToNode[[2]int{0, 0}], posToNode[[2]int{7, 0}]
+
+	abstractPath, err := grid.FindAbstractPath(start, end)
+	assert.NoError(t, err)
+	path, err := grid.Refine(abstractPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, NewPath(path, nil).Cost)
+
+	// Build a wall between the clusters with a gap at the very bottom.
+	for y := 0; y < 3; y++ {
+		disconnect(posToNode[[2]int{3, y}], posToNode[[2]int{4, y}])
+	}
+	// The old rough path can no longer be followed.
+	_, err = grid.Refine(abstractPath)
+	assert.Error(t, err)
+
+	err = grid.Update([2]int{3, 0}, [2]int{3, 1}, [2]int{3, 2})
+	assert.NoError(t, err)
+	path, err = grid.FindPath(start, end)
+	assert.NoError(t, err)
+	assertPathConnected(t, path)
+	assert.Contains(t, path, posToNode[[2]int{3, 3}])
+	assert.Contains(t, path, posToNode[[2]int{4, 3}])
+	assert.Equal(t, 13, NewPath(path, nil).Cost)
+
+	// Make the way along the bottom expensive. Only costs inside a cluster change.
+	for x := 4; x < 7; x++ {
+		posToNode[[2]int{x, 3}].Cost = 10
+	}
+	err = grid.Update([2]int{4, 3})
+	assert.NoError(t, err)
+	path, err = grid.FindPath(start, end)
+	assert.NoError(t, err)
+	assert.NotContains(t, path, posToNode[[2]int{5, 3}])
+	assert.Equal(t, 22, NewPath(path, nil).Cost)
+}
+
+func TestHierarchicalGridFailure(t *testing.T) {
+	_, posToNode, err := CreateRegular2DGrid([2]int{4, 4}, fourNeighbours, "default", 1)
+	assert.NoError(t, err)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+
+	_, err = NewHierarchicalGrid(posToNode, 0)
+	assert.Error(t, err)
+
+	grid, err := NewHierarchicalGrid(posToNode, 2)
+	assert.NoError(t, err)
+	_, err = grid.FindPath(outside, posToNode[[2]int{0, 0}])
+	assert.Error(t, err)
+	_, err = grid.FindPath(posToNode[[2]int{0, 0}], outside)
+	assert.Error(t, err)
+	_, err = grid.Refine([]*Node{})
+	assert.Error(t, err)
+	_, err = grid.Refine([]*Node{outside, posToNode[[2]int{0, 0}]})
+	assert.Error(t, err)
+	err = grid.Update([2]int{4, 4})
+	assert.Error(t, err)
+
+	// Isolate a corner.
+	corner := posToNode[[2]int{3, 3}]
+	disconnect(corner, posToNode[[2]int{2, 3}])
+	disconnect(corner, posToNode[[2]int{3, 2}])
+	err = grid.Update([2]int{3, 3})
+	assert.NoError(t, err)
+	_, err = grid.FindPath(posToNode[[2]int{0, 0}], corner)
+	assert.True(t, errors.Is(err, errNoPath))
+
+	// Connections may not skip clusters.
+	grid, err = NewHierarchicalGrid(posToNode, 1)
+	assert.NoError(t, err)
+	posToNode[[2]int{0, 0}].AddConnection(corner)
+	err = grid.Update([2]int{0, 0})
+	assert.Error(t, err)
+	_, err = NewHierarchicalGrid(posToNode, 1)
+	assert.Error(t, err)
+
+	// Nodes may only be at a single position.
+	posToNode[[2]int{5, 5}] = corner
+	_, err = NewHierarchicalGrid(posToNode, 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,243 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// These are the colours used by RenderImage. Shades are brightnesses in the range [0, 255].
+const (
+	imageMaxShade = 255
+	// Cells are drawn in shades of grey from white for the cheapest to this shade for the most
+	// expensive ones.
+	imageDarkestShade = 64
+	// Explored cells are drawn in shades of blue from the first to the last one expanded.
+	imageFirstExploredShade = 224
+	imageLastExploredShade  = 32
+)
+
+var (
+	imagePathColour = color.RGBA{R: imageMaxShade, A: imageMaxShade}
+	imageWallColour = color.RGBA{A: imageMaxShade}
+)
+
+// ImageOption configures the output of RenderImage and WritePNG. Obtain options via the functions
+// starting with "WithImage", e.g. WithImageScale.
+type ImageOption func(*imageOptions)
+
+// Type imageOptions collects the settings of all options passed to RenderImage.
+type imageOptions struct {
+	scale    int
+	explored map[*Node]int
+	yUp      bool
+}
+
+// WithImageScale draws each cell as a square of `pixels` times `pixels` pixels instead of a single
+// pixel. The value has to be positive.
+func WithImageScale(pixels int) ImageOption {
+	return func(o *imageOptions) {
+		o.scale = pixels
+	}
+}
+
+// WithImageExplored shades explored nodes by the order they were expanded in, e.g. the expanded
+// nodes recorded via WithTrace. The first one expanded is drawn in light blue, the last one in dark
+// blue.
+func WithImageExplored(nodes []*Node) ImageOption {
+	return func(o *imageOptions) {
+		for idx, node := range nodes {
+			o.explored[node] = idx
+		}
+	}
+}
+
+// WithImageYUp puts the row with the largest y coordinate at the top, just like WithRenderYUp.
+func WithImageYUp() ImageOption {
+	return func(o *imageOptions) {
+		o.yUp = true
+	}
+}
+
+// Function shade interpolates linearly between two shades. It returns `from` for `val == min` and
+// `to` for `val == max`.
+func shade(val, min, max, from, to int) uint8 {
+	if max <= min {
+		return uint8(from)
+	}
+	return uint8(from + (val-min)*(to-from)/(max-min))
+}
+
+// Method colour determines the colour of a node that is not on the path.
+func (o *imageOptions) colour(node *Node, minCost, maxCost int) color.RGBA {
+	if order, explored := o.explored[node]; explored {
+		val := shade(
+			order, 0, len(o.explored)-1, imageFirstExploredShade, imageLastExploredShade,
+		)
+		return color.RGBA{R: val, G: val, B: imageMaxShade, A: imageMaxShade}
+	}
+	if len(node.connections) == 0 {
+		return imageWallColour
+	}
+	val := shade(node.Cost, minCost, maxCost, imageMaxShade, imageDarkestShade)
+	return color.RGBA{R: val, G: val, B: val, A: imageMaxShade}
+}
+
+// RenderImage draws a 2D grid as an image, e.g. to compare the nodes explored with different
+// heuristics. It takes the same arguments as RenderGrid and also lays out cells the same way, one
+// pixel per cell by default. Use WritePNG to store the image directly.
+//
+// Cells are drawn as a heatmap of node costs in shades of grey, from white for the cheapest to dark
+// grey for the most expensive ones. Nodes without any connections are drawn black, nodes on the
+// path red. Positions without a node are transparent.
+func RenderImage(
+	posToNode map[[2]int]*Node, path []*Node, opts ...ImageOption,
+) (*image.RGBA, error) {
+	options := imageOptions{scale: 1, explored: map[*Node]int{}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.scale < 1 {
+		return nil, fmt.Errorf("image scale has to be positive")
+	}
+	if len(posToNode) == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0)), nil
+	}
+	onPath := make(map[*Node]bool, len(path))
+	for _, node := range path {
+		onPath[node] = true
+	}
+	minPos, maxPos := gridBounds(posToNode)
+	minCost, maxCost := costBounds(posToNode)
+
+	width, height := maxPos[0]-minPos[0]+1, maxPos[1]-minPos[1]+1
+	img := image.NewRGBA(image.Rect(0, 0, width*options.scale, height*options.scale))
+	for pos, node := range posToNode {
+		colour := imagePathColour
+		if !onPath[node] {
+			colour = options.colour(node, minCost, maxCost)
+		}
+		col, row := pos[0]-minPos[0], pos[1]-minPos[1]
+		if options.yUp {
+			row = height - 1 - row
+		}
+		for x := col * options.scale; x < (col+1)*options.scale; x++ {
+			for y := row * options.scale; y < (row+1)*options.scale; y++ {
+				img.SetRGBA(x, y, colour)
+			}
+		}
+	}
+	return img, nil
+}
+
+// Function costBounds determines the lowest and highest cost of all nodes that have connections.
+func costBounds(posToNode map[[2]int]*Node) (int, int) {
+	first := true
+	minCost, maxCost := 0, 0
+	for _, node := range posToNode {
+		if len(node.connections) == 0 {
+			continue
+		}
+		if first || node.Cost < minCost {
+			minCost = node.Cost
+		}
+		if first || node.Cost > maxCost {
+			maxCost = node.Cost
+		}
+		first = false
+	}
+	return minCost, maxCost
+}
+
+// WritePNG draws a 2D grid as an image via RenderImage and writes it to a writer in the PNG format.
+func WritePNG(
+	writer io.Writer, posToNode map[[2]int]*Node, path []*Node, opts ...ImageOption,
+) error {
+	img, err := RenderImage(posToNode, path, opts...)
+	if err != nil {
+		return err
+	}
+	return png.Encode(writer, img)
+}
+
+// CreateGridFromImage creates a regular 2D grid with one node per pixel of an image, e.g. a cost
+// map painted in an image editor. The x coordinate is the column and the y coordinate is the row,
+// both starting at zero at the top left. The arguments `connections` and `graphType` work just like
+// for CreateRegular2DGrid and so do node IDs.
+//
+// Each pixel is converted to grey. Its intensity, i.e. its brightness from 0 for black to 255 for
+// white, becomes the payload of the node, as a uint8. The cost of the node is determined by calling
+// `cost` with the intensity. If `cost` is nil, the intensity itself is used as cost. Pixels whose
+// intensity is below `threshold` are impassable. Such pixels still get a node, but that node is not
+// connected to any other one. Use a threshold of zero if all pixels can be entered.
+//
+// CreateGridFromImage returns three values:
+// 1. A graph object suitable for path finding via FindPath.
+// 2. A map from node positions to node pointers, just like for CreateRegular2DGrid.
+// 3. An error value in case there were problems, e.g. if `cost` returned a negative value.
+func CreateGridFromImage(
+	img image.Image,
+	connections [][2]int,
+	graphType string,
+	cost func(intensity uint8) int,
+	threshold uint8,
+) (GraphOps, map[[2]int]*Node, error) {
+	bounds := img.Bounds()
+	size := [2]int{bounds.Dx(), bounds.Dy()}
+	graph, posToNode, err := CreateRegular2DGrid(size, connections, graphType, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	for pos, node := range posToNode {
+		pixel := img.At(bounds.Min.X+pos[0], bounds.Min.Y+pos[1])
+		intensity := color.GrayModel.Convert(pixel).(color.Gray).Y
+		node.Payload = intensity
+		if intensity < threshold {
+			isolate(node)
+			continue
+		}
+		node.Cost = int(intensity)
+		if cost != nil {
+			node.Cost = cost(intensity)
+		}
+		if node.Cost < 0 {
+			return nil, nil, fmt.Errorf("negative cost for intensity %d", intensity)
+		}
+	}
+	return graph, posToNode, nil
+}
+
+// ReadImageGrid works just like CreateGridFromImage but reads the image from a reader. The image
+// can be in the PNG format or any other format registered with the image package.
+func ReadImageGrid(
+	reader io.Reader,
+	connections [][2]int,
+	graphType string,
+	cost func(intensity uint8) int,
+	threshold uint8,
+) (GraphOps, map[[2]int]*Node, error) {
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read image: %s", err.Error())
+	}
+	return CreateGridFromImage(img, connections, graphType, cost, threshold)
+}
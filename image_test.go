@@ -0,0 +1,142 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func grey(val uint8) color.RGBA {
+	return color.RGBA{R: val, G: val, B: val, A: 255}
+}
+
+func TestRenderImage(t *testing.T) {
+	_, posToNode, markers, err := ParseGrid("S1#\n592\n.3E", fourNeighbours, "heaped",
+		WithGridDigitCosts(),
+	)
+	assert.NoError(t, err)
+	path := []*Node{posToNode[markers['S']], posToNode[[2]int{0, 1}]}
+
+	img, err := RenderImage(posToNode, path)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 3, 3), img.Bounds())
+	expected := [][]color.RGBA{
+		{imagePathColour, grey(255), imageWallColour},
+		{imagePathColour, grey(64), grey(232)},
+		{grey(255), grey(208), grey(255)},
+	}
+	for y, row := range expected {
+		for x, colour := range row {
+			assert.Equal(t, colour, img.RGBAAt(x, y), "x:%d,y:%d", x, y)
+		}
+	}
+
+	img, err = RenderImage(posToNode, nil, WithImageYUp(), WithImageScale(2))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 6, 6), img.Bounds())
+	for _, pos := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		assert.Equal(t, grey(255), img.RGBAAt(pos[0], pos[1]))
+	}
+	assert.Equal(t, imageWallColour, img.RGBAAt(5, 5))
+}
+
+func TestRenderImageExplored(t *testing.T) {
+	_, posToNode, err := CreateRegular2DGrid([2]int{3, 1}, fourNeighbours, "default", 0)
+	assert.NoError(t, err)
+	delete(posToNode, [2]int{1, 0})
+	explored := []*Node{posToNode[[2]int{2, 0}], posToNode[[2]int{0, 0}]}
+
+	img, err := RenderImage(posToNode, nil, WithImageExplored(explored))
+	assert.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 224, G: 224, B: 255, A: 255}, img.RGBAAt(2, 0))
+	assert.Equal(t, color.RGBA{R: 32, G: 32, B: 255, A: 255}, img.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{}, img.RGBAAt(1, 0))
+}
+
+func TestWritePNG(t *testing.T) {
+	_, posToNode, err := CreateRegular2DGrid([2]int{4, 2}, fourNeighbours, "default", 3)
+	assert.NoError(t, err)
+
+	buffer := bytes.Buffer{}
+	assert.NoError(t, WritePNG(&buffer, posToNode, nil, WithImageScale(3)))
+	img, err := png.Decode(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 12, 6), img.Bounds())
+
+	assert.Error(t, WritePNG(&buffer, posToNode, nil, WithImageScale(0)))
+	img, err = RenderImage(map[[2]int]*Node{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, img.Bounds().Dx())
+}
+
+func TestCreateGridFromImage(t *testing.T) {
+	img := image.NewGray(image.Rect(10, 20, 13, 22))
+	img.SetGray(10, 20, color.Gray{Y: 200})
+	img.SetGray(11, 20, color.Gray{Y: 5})
+	img.SetGray(12, 21, color.Gray{Y: 30})
+
+	for _, graphType := range []string{"default", "heaped", "bucket"} {
+		graph, posToNode, err := CreateGridFromImage(img, fourNeighbours, graphType, nil, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, graph.Len())
+		assert.Equal(t, 200, posToNode[[2]int{0, 0}].Cost)
+		assert.Equal(t, uint8(200), posToNode[[2]int{0, 0}].Payload)
+		assert.Equal(t, 30, posToNode[[2]int{2, 1}].Cost)
+		assert.Equal(t, 0, posToNode[[2]int{0, 1}].Cost)
+		assert.Equal(t, 2, len(posToNode[[2]int{0, 0}].connections))
+	}
+
+	halve := func(intensity uint8) int { return int(intensity) / 2 }
+	_, posToNode, err := CreateGridFromImage(img, fourNeighbours, "heaped", halve, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, posToNode[[2]int{0, 0}].Cost)
+	assert.Equal(t, 2, posToNode[[2]int{1, 0}].Cost)
+	assert.Equal(t, 15, posToNode[[2]int{2, 1}].Cost)
+	// Only pixels below the threshold are impassable.
+	assert.Empty(t, posToNode[[2]int{0, 1}].connections)
+	assert.Equal(t, []*Node{posToNode[[2]int{1, 0}]}, posToNode[[2]int{0, 0}].connections)
+
+	negative := func(uint8) int { return -1 }
+	_, _, err = CreateGridFromImage(img, fourNeighbours, "heaped", negative, 0)
+	assert.Error(t, err)
+	_, _, err = CreateGridFromImage(img, fourNeighbours, "unknownType", nil, 0)
+	assert.Error(t, err)
+}
+
+func TestReadImageGrid(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.SetRGBA(0, 0, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	img.SetRGBA(1, 0, color.RGBA{R: 100, G: 100, B: 100, A: 255})
+	buffer := bytes.Buffer{}
+	assert.NoError(t, png.Encode(&buffer, img))
+
+	graph, posToNode, err := ReadImageGrid(&buffer, fourNeighbours, "heaped", nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, graph.Len())
+	assert.Equal(t, 255, posToNode[[2]int{0, 0}].Cost)
+	assert.Equal(t, 100, posToNode[[2]int{1, 0}].Cost)
+
+	_, _, err = ReadImageGrid(bytes.NewBufferString("no image"), fourNeighbours, "heaped", nil, 0)
+	assert.Error(t, err)
+}
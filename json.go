@@ -0,0 +1,145 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONGraph describes the JSON representation of a graph used by WriteJSON and ReadJSON. A graph
+// with two nodes connected in both directions looks like this:
+//
+//	{
+//	  "nodes": [
+//	    {"id": "a", "cost": 1, "connections": ["b"]},
+//	    {"id": "b", "cost": 2, "connections": ["a"], "payload": {"name": "door"}}
+//	  ]
+//	}
+//
+// Node IDs have to be unique since connections refer to nodes via their IDs. Connections are
+// one-way. Thus, a connection in both directions has to be listed for both nodes.
+type JSONGraph struct {
+	// Nodes lists all nodes of the graph.
+	Nodes []JSONNode `json:"nodes"`
+}
+
+// JSONNode describes the JSON representation of a single node. See JSONGraph.
+type JSONNode struct {
+	// ID is the node's ID. It has to be unique within the graph.
+	ID string `json:"id"`
+	// Cost is the node's cost. It must not be negative.
+	Cost int `json:"cost"`
+	// Connections lists the IDs of all nodes this one is connected to in the order they were added.
+	// It may be left out if there are no connections.
+	Connections []string `json:"connections,omitempty"`
+	// Payload is the node's payload. It can be any JSON value and may be left out.
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// WriteJSON writes a graph to a writer in the JSON format described by JSONGraph. Nodes are sorted
+// by their IDs. Payloads are converted via encoding/json. All nodes need unique IDs and may only be
+// connected to nodes in the same graph. Otherwise, an error is returned.
+func WriteJSON(writer io.Writer, graph GraphOps) error {
+	nodes := make([]*Node, 0, graph.Len())
+	err := graph.Apply(func(node *Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sortNodesByID(nodes)
+
+	result := JSONGraph{Nodes: make([]JSONNode, 0, len(nodes))}
+	for idx, node := range nodes {
+		if idx > 0 && nodes[idx-1].ID == node.ID {
+			return fmt.Errorf("duplicate node id %s", node.ID)
+		}
+		jsonNode := JSONNode{ID: node.ID, Cost: node.Cost}
+		for _, neigh := range node.connections {
+			if !graph.Has(neigh) {
+				return fmt.Errorf("node %s connected to node %s outside graph", node.ID, neigh.ID)
+			}
+			jsonNode.Connections = append(jsonNode.Connections, neigh.ID)
+		}
+		if node.Payload != nil {
+			jsonNode.Payload, err = json.Marshal(node.Payload)
+			if err != nil {
+				return fmt.Errorf("cannot convert payload of node %s: %s", node.ID, err.Error())
+			}
+		}
+		result.Nodes = append(result.Nodes, jsonNode)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// ReadJSON reads a graph in the JSON format described by JSONGraph from a reader. Also provide the
+// name of the type of graph you want to obtain, just like for CreateRegular2DGrid. Payloads are
+// stored as json.RawMessage, which you can convert to your own type via json.Unmarshal. Nodes
+// without a payload or with a null payload get a nil payload.
+//
+// ReadJSON returns three values:
+// 1. A graph object suitable for path finding via FindPath.
+// 2. A map from node IDs to node pointers.
+// 3. An error value in case there were problems, e.g. with duplicate IDs or unknown connections.
+func ReadJSON(reader io.Reader, graphType string) (GraphOps, map[string]*Node, error) {
+	input := JSONGraph{}
+	if err := json.NewDecoder(reader).Decode(&input); err != nil {
+		return nil, nil, fmt.Errorf("cannot parse graph: %s", err.Error())
+	}
+	graph, err := newGraphOfType(graphType, len(input.Nodes))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idToNode := make(map[string]*Node, len(input.Nodes))
+	for _, jsonNode := range input.Nodes {
+		if _, exists := idToNode[jsonNode.ID]; exists {
+			return nil, nil, fmt.Errorf("duplicate node id %s", jsonNode.ID)
+		}
+		var payload interface{}
+		if jsonNode.Payload != nil && string(jsonNode.Payload) != "null" {
+			payload = jsonNode.Payload
+		}
+		node, err := NewNode(jsonNode.ID, jsonNode.Cost, len(jsonNode.Connections), payload)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create node %s: %s", jsonNode.ID, err.Error())
+		}
+		idToNode[jsonNode.ID] = node
+		graph.Add(node)
+	}
+	// Connections can only be added once all nodes exist.
+	for _, jsonNode := range input.Nodes {
+		node := idToNode[jsonNode.ID]
+		for _, neighID := range jsonNode.Connections {
+			neigh, exists := idToNode[neighID]
+			if !exists {
+				return nil, nil, fmt.Errorf(
+					"node %s connected to unknown node %s", jsonNode.ID, neighID,
+				)
+			}
+			node.AddConnection(neigh)
+		}
+	}
+	return graph, idToNode, nil
+}
@@ -0,0 +1,170 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type jsonTestPayload struct {
+	Name  string `json:"name"`
+	Doors []int  `json:"doors"`
+}
+
+func TestWriteJSON(t *testing.T) {
+	graph := NewHeapedGraph(0)
+	nodeB, err := NewNode("b", 2, 0, jsonTestPayload{Name: "hall", Doors: []int{1, 2}})
+	assert.NoError(t, err)
+	nodeA, err := NewNode("a", 1, 0, nil)
+	assert.NoError(t, err)
+	nodeC, err := NewNode("c", 0, 0, "plain")
+	assert.NoError(t, err)
+	graph.Add(nodeB)
+	graph.Add(nodeA)
+	graph.Add(nodeC)
+	nodeA.AddPairwiseConnection(nodeB)
+	nodeA.AddConnection(nodeC)
+
+	buffer := bytes.Buffer{}
+	err = WriteJSON(&buffer, graph)
+	assert.NoError(t, err)
+	expected := `{
+  "nodes": [
+    {
+      "id": "a",
+      "cost": 1,
+      "connections": [
+        "b",
+        "c"
+      ]
+    },
+    {
+      "id": "b",
+      "cost": 2,
+      "connections": [
+        "a"
+      ],
+      "payload": {
+        "name": "hall",
+        "doors": [
+          1,
+          2
+        ]
+      }
+    },
+    {
+      "id": "c",
+      "cost": 0,
+      "payload": "plain"
+    }
+  ]
+}
+`
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestWriteJSONFailure(t *testing.T) {
+	buffer := bytes.Buffer{}
+
+	graph := NewGraph(0)
+	node1, err := NewNode("same", 0, 0, nil)
+	assert.NoError(t, err)
+	node2, err := NewNode("same", 0, 0, nil)
+	assert.NoError(t, err)
+	graph.Add(node1)
+	graph.Add(node2)
+	assert.Error(t, WriteJSON(&buffer, graph))
+
+	graph = NewGraph(0)
+	graph.Add(node1)
+	node1.AddConnection(node2)
+	assert.Error(t, WriteJSON(&buffer, graph))
+
+	graph = NewGraph(0)
+	node3, err := NewNode("func", 0, 0, func() {})
+	assert.NoError(t, err)
+	graph.Add(node3)
+	assert.Error(t, WriteJSON(&buffer, graph))
+
+	assert.Error(t, WriteJSON(&buffer, &failingApplyGraph{}))
+}
+
+func TestReadJSON(t *testing.T) {
+	input := `{"nodes": [
+		{"id": "a", "cost": 1, "connections": ["c", "b"]},
+		{"id": "b", "cost": 2, "connections": ["a"], "payload": {"name": "hall", "doors": [1]}},
+		{"id": "c", "cost": 3, "payload": null}
+	]}`
+	for _, graphType := range []string{"default", "heaped", "bucket"} {
+		graph, idToNode, err := ReadJSON(strings.NewReader(input), graphType)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, graph.Len())
+		assert.Equal(t, 3, len(idToNode))
+
+		nodeA, nodeB, nodeC := idToNode["a"], idToNode["b"], idToNode["c"]
+		assert.Equal(t, "b", nodeB.ID)
+		assert.Equal(t, 2, nodeB.Cost)
+		assert.Equal(t, []*Node{nodeC, nodeB}, nodeA.connections)
+		assert.Equal(t, []*Node{nodeA}, nodeB.connections)
+		assert.Empty(t, nodeC.connections)
+		assert.Nil(t, nodeA.Payload)
+		assert.Nil(t, nodeC.Payload)
+
+		payload := jsonTestPayload{}
+		assert.NoError(t, json.Unmarshal(nodeB.Payload.(json.RawMessage), &payload))
+		assert.Equal(t, jsonTestPayload{Name: "hall", Doors: []int{1}}, payload)
+
+		path, err := FindPath(graph, nodeB, nodeC, zeroHeuristic)
+		assert.NoError(t, err)
+		assert.Equal(t, []*Node{nodeB, nodeA, nodeC}, path)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	graph, _, err := CreateRegular2DGrid([2]int{4, 3}, fourNeighbours, "default", 2)
+	assert.NoError(t, err)
+	first := bytes.Buffer{}
+	assert.NoError(t, WriteJSON(&first, graph))
+
+	readGraph, _, err := ReadJSON(bytes.NewReader(first.Bytes()), "default")
+	assert.NoError(t, err)
+	second := bytes.Buffer{}
+	assert.NoError(t, WriteJSON(&second, readGraph))
+	assert.Equal(t, first.String(), second.String())
+}
+
+func TestReadJSONFailure(t *testing.T) {
+	inputs := []string{
+		`not json`,
+		`{"nodes": [{"id": "a", "cost": -1}]}`,
+		`{"nodes": [{"id": "a"}, {"id": "a"}]}`,
+		`{"nodes": [{"id": "a", "connections": ["b"]}]}`,
+	}
+	for _, input := range inputs {
+		_, _, err := ReadJSON(strings.NewReader(input), "default")
+		assert.Error(t, err, input)
+	}
+
+	_, _, err := ReadJSON(strings.NewReader(`{"nodes": []}`), "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+)
+
+// LandmarkHeuristic provides heuristics based on landmarks, also known as ALT (A*, landmarks,
+// triangle inequality). The exact costs from and to a few landmark nodes are precomputed once. For
+// any end node, the triangle inequality then yields a lower bound for the remaining cost from every
+// node. The resulting heuristics never over-estimate and do not need any geometric information,
+// which makes them suitable for large, static graphs such as road networks.
+//
+// Obtain one via NewLandmarkHeuristic or NewLandmarkHeuristicFrom. The precomputed costs are only
+// valid as long as neither costs nor connections change.
+type LandmarkHeuristic struct {
+	landmarks []*Node
+	// Member from holds, for each landmark, the cost from the landmark to every node reachable
+	// from it. Member to holds the cost from every node that can reach the landmark to the
+	// landmark.
+	from []map[*Node]int
+	to   []map[*Node]int
+}
+
+// NewLandmarkHeuristicFrom precomputes a landmark heuristic using the given landmarks. All of them
+// have to be in the graph. Landmarks at the border of the graph, far away from each other, usually
+// yield the best estimates.
+func NewLandmarkHeuristicFrom(graph GraphOps, landmarks []*Node) (*LandmarkHeuristic, error) {
+	if len(landmarks) == 0 {
+		return nil, fmt.Errorf("input sanitation: need at least one landmark")
+	}
+	for _, landmark := range landmarks {
+		if !graph.Has(landmark) {
+			return nil, fmt.Errorf("input sanitation: landmark not in graph")
+		}
+	}
+	reverse, err := reverseExpand(graph)
+	if err != nil {
+		return nil, err
+	}
+	result := &LandmarkHeuristic{}
+	for _, landmark := range landmarks {
+		result.add(landmark, reverse)
+	}
+	return result, nil
+}
+
+// NewLandmarkHeuristic precomputes a landmark heuristic with `numLandmarks` landmarks chosen
+// automatically. The landmarks are chosen one after the other, each as far away as possible from
+// the ones chosen before (farthest-point selection). The first one is the node farthest away from
+// the node with the alphabetically first ID. Nodes that cannot be reached from or reach any chosen
+// landmark are preferred.
+func NewLandmarkHeuristic(graph GraphOps, numLandmarks int) (*LandmarkHeuristic, error) {
+	if numLandmarks <= 0 {
+		return nil, fmt.Errorf("input sanitation: need at least one landmark")
+	}
+	if numLandmarks > graph.Len() {
+		return nil, fmt.Errorf("input sanitation: more landmarks than nodes requested")
+	}
+	nodes := make([]*Node, 0, graph.Len())
+	err := graph.Apply(func(node *Node) error {
+		nodes = append(nodes, node)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortNodesByID(nodes)
+	reverse, err := reverseExpand(graph)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start with the node farthest away from an arbitrary but fixed one. That one is removed again
+	// afterwards.
+	result := &LandmarkHeuristic{}
+	result.add(nodes[0], reverse)
+	first := result.farthest(nodes)
+	result = &LandmarkHeuristic{}
+	result.add(first, reverse)
+
+	for len(result.landmarks) < numLandmarks {
+		result.add(result.farthest(nodes), reverse)
+	}
+	return result, nil
+}
+
+// Method add adds a landmark and computes the costs from and to it.
+func (l *LandmarkHeuristic) add(landmark *Node, reverse expandFn) {
+	from, _ := dijkstra(landmark, forwardExpand, nil)
+	to, _ := dijkstra(landmark, reverse, nil)
+	l.landmarks = append(l.landmarks, landmark)
+	l.from = append(l.from, from)
+	l.to = append(l.to, to)
+}
+
+// Method farthest finds the node that is farthest away from all current landmarks. The distance of
+// a node to a landmark is the cost from the landmark to the node or, if it cannot be reached, the
+// one from the node to the landmark. Nodes without any known distance are the farthest away. Among
+// equally distant ones, the first in the given slice is chosen.
+func (l *LandmarkHeuristic) farthest(nodes []*Node) *Node {
+	var result *Node
+	resultDist, resultUnknown := -1, false
+	for _, node := range nodes {
+		dist, unknown := -1, true
+		for idx := range l.landmarks {
+			landmarkDist, found := l.from[idx][node]
+			if !found {
+				landmarkDist, found = l.to[idx][node]
+			}
+			if found && (unknown || landmarkDist < dist) {
+				dist, unknown = landmarkDist, false
+			}
+		}
+		better := (unknown && !resultUnknown) || (unknown == resultUnknown && dist > resultDist)
+		if result == nil || better {
+			result, resultDist, resultUnknown = node, dist, unknown
+		}
+	}
+	return result
+}
+
+// Landmarks returns the landmarks used.
+func (l *LandmarkHeuristic) Landmarks() []*Node {
+	return append([]*Node{}, l.landmarks...)
+}
+
+// Estimate determines a lower bound for the cost of getting from one node to another. It is the
+// largest bound the triangle inequality yields for any landmark, or zero if there is none.
+func (l *LandmarkHeuristic) Estimate(from, to *Node) int {
+	result := 0
+	for idx := range l.landmarks {
+		// Going via `from` to `to` is at least as expensive as going to `to` directly.
+		fromLandmarkTo, foundTo := l.from[idx][to]
+		fromLandmarkFrom, foundFrom := l.from[idx][from]
+		if foundTo && foundFrom && fromLandmarkTo-fromLandmarkFrom > result {
+			result = fromLandmarkTo - fromLandmarkFrom
+		}
+		// Going from `from` via `to` to the landmark is at least as expensive as going directly.
+		toLandmarkFrom, foundFrom := l.to[idx][from]
+		toLandmarkTo, foundTo := l.to[idx][to]
+		if foundTo && foundFrom && toLandmarkFrom-toLandmarkTo > result {
+			result = toLandmarkFrom - toLandmarkTo
+		}
+	}
+	return result
+}
+
+// Heuristic obtains a heuristic for the given end node that is suitable for use with FindPath. The
+// method can be used as a HeuristicFactory. Obtaining a heuristic is cheap, the estimates are
+// computed on demand.
+func (l *LandmarkHeuristic) Heuristic(end *Node) Heuristic {
+	return func(node *Node) int {
+		return l.Estimate(node, end)
+	}
+}
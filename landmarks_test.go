@@ -0,0 +1,146 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Create a grid with varying but reproducible costs.
+func setUpVaryingGrid(t *testing.T, size int, graphType string) (GraphOps, map[[2]int]*Node) {
+	graph, posToNode, err := CreateRegular2DGrid([2]int{size, size}, fourNeighbours, graphType, 0)
+	assert.NoError(t, err)
+	for pos, node := range posToNode {
+		node.Cost = (pos[0]*7+pos[1]*13)%10 + 1
+	}
+	return graph, posToNode
+}
+
+func TestNewLandmarkHeuristicSelection(t *testing.T) {
+	graph, posToNode := setUpVaryingGrid(t, 10, "default")
+
+	landmarks, err := NewLandmarkHeuristic(graph, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(landmarks.Landmarks()))
+	// Farthest-point selection ends up at the borders of the grid.
+	for _, landmark := range landmarks.Landmarks() {
+		onBorder := false
+		for pos, node := range posToNode {
+			if node == landmark {
+				onBorder = pos[0] == 0 || pos[0] == 9 || pos[1] == 0 || pos[1] == 9
+			}
+		}
+		assert.True(t, onBorder, landmark.ToString())
+	}
+}
+
+func TestLandmarkHeuristicAdmissible(t *testing.T) {
+	graph, posToNode := setUpVaryingGrid(t, 10, "heaped")
+
+	landmarks, err := NewLandmarkHeuristic(graph, 4)
+	assert.NoError(t, err)
+
+	start := posToNode[[2]int{0, 0}]
+	for _, endPos := range [][2]int{[2]int{9, 9}, [2]int{4, 7}, [2]int{0, 0}} {
+		end := posToNode[endPos]
+		heuristic := landmarks.Heuristic(end)
+		assert.Equal(t, 0, heuristic(end))
+
+		report, err := CheckHeuristic(graph, end, heuristic)
+		assert.NoError(t, err)
+		assert.True(t, report.Admissible(), report.ToString())
+		assert.True(t, report.Consistent(), report.ToString())
+
+		if start == end {
+			continue
+		}
+		// The path found is as cheap as the one found without a heuristic.
+		path, err := FindPath(graph, start, end, heuristic)
+		assert.NoError(t, err)
+		referencePath, err := FindPath(graph, start, end, zeroHeuristic)
+		assert.NoError(t, err)
+		assert.Equal(t, NewPath(referencePath, nil).Cost, NewPath(path, nil).Cost)
+	}
+	// Some estimates are actually useful.
+	assert.NotZero(t, landmarks.Estimate(start, posToNode[[2]int{9, 9}]))
+}
+
+func TestNewLandmarkHeuristicFrom(t *testing.T) {
+	graph, nodeA, nodeB, nodeC := setUpChain(t)
+
+	landmarks, err := NewLandmarkHeuristicFrom(graph, []*Node{nodeA})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{nodeA}, landmarks.Landmarks())
+	// The chain a->b->c costs 2 and 3 for the two steps.
+	assert.Equal(t, 3, landmarks.Estimate(nodeB, nodeC))
+	assert.Equal(t, 5, landmarks.Estimate(nodeA, nodeC))
+	// Nothing is known about going backwards.
+	assert.Equal(t, 0, landmarks.Estimate(nodeC, nodeA))
+
+	landmarks, err = NewLandmarkHeuristicFrom(graph, []*Node{nodeC})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, landmarks.Heuristic(nodeC)(nodeB))
+}
+
+func TestNewLandmarkHeuristicDisconnected(t *testing.T) {
+	graph, nodeA, _, _ := setUpChain(t)
+	lonely, err := NewNode("lonely", 0, 0, nil)
+	assert.NoError(t, err)
+	graph.Add(lonely)
+
+	landmarks, err := NewLandmarkHeuristic(graph, 2)
+
+	assert.NoError(t, err)
+	// Node a is the one starting the selection. The node that cannot be reached from it is farther
+	// away than any other one. From there, no node can be reached and the first one is chosen.
+	assert.Equal(t, []*Node{lonely, nodeA}, landmarks.Landmarks())
+}
+
+func TestLandmarkHeuristicFailure(t *testing.T) {
+	graph, _, _, _ := setUpChain(t)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+
+	_, err = NewLandmarkHeuristic(graph, 0)
+	assert.Error(t, err)
+	_, err = NewLandmarkHeuristic(graph, 4)
+	assert.Error(t, err)
+	_, err = NewLandmarkHeuristicFrom(graph, nil)
+	assert.Error(t, err)
+	_, err = NewLandmarkHeuristicFrom(graph, []*Node{outside})
+	assert.Error(t, err)
+
+	failing := &failingApplyGraph{}
+	_, err = NewLandmarkHeuristicFrom(failing, []*Node{outside})
+	assert.Error(t, err)
+	_, err = NewLandmarkHeuristic(&failingApplyLenGraph{}, 1)
+	assert.Error(t, err)
+}
+
+// A graph whose Apply always fails but that claims to contain nodes.
+type failingApplyLenGraph struct {
+	failingApplyGraph
+}
+
+func (g *failingApplyLenGraph) Len() int {
+	return 1
+}
@@ -19,16 +19,32 @@ package astar
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"sync/atomic"
 )
 
 const (
 	defaultCost = 0
 )
 
+// Variable nodeSerial counts the nodes created so far. It is used to give each node a unique serial
+// number.
+var nodeSerial uint64
+
+// Variable stampCounter counts the stamps handed out so far. Stamps identify searches and the lists
+// used by them. The zero value is never handed out.
+var stampCounter uint64
+
+// Function nextStamp obtains a new, unique stamp.
+func nextStamp() uint64 {
+	return atomic.AddUint64(&stampCounter, 1)
+}
+
 // Node is a node for a connected graph along which to travel. Use NewNode to create one. It *will*
-// be modified while the algorithm is being executed but FindPath reverts it to its original state
-// at the end.
+// be modified while the algorithm is being executed. Instead of being reverted at the end, the
+// modifications are tagged with the search that made them and ignored by any later search. That
+// way, a search only takes time for the nodes it actually explores.
 type Node struct {
 	// Public members follow.
 	// ID identifies the node. It is just a nice representation for the user and not used by the
@@ -40,16 +56,33 @@ type Node struct {
 	// example. Type checks are the user's obligation.
 	Payload interface{}
 	// Private members follow.
-	// Member connections determines which nodes this one is connected to.
-	connections Graph
+	// Member connections determines which nodes this one is connected to. They are stored in the
+	// order the connections were added, which makes the algorithm deterministic. Member connected
+	// contains the same nodes for fast lookups.
+	connections []*Node
+	connected   Graph
+	// Member serial is unique for each node and increases in the order nodes are created. It is
+	// used to break ties deterministically.
+	serial uint64
 	// Member trackedCost tracks the accumulated minimal cost for reaching this node. If the prev
 	// member is still nil, the algorithm assumes that no costs have been tracked yet.
 	trackedCost int
 	// Member prev tracks the previous node on the minimal cost connection.
 	prev *Node
+	// Member generation is the stamp of the search that reached this node last. Members prev and
+	// trackedCost are only meaningful for that search and are reset once another search reaches
+	// this node.
+	generation uint64
+	// Member list is the stamp of the search list this node is in, see NewEmpty of HeapedGraph.
+	// Since search lists are never reused, an outdated stamp is no problem.
+	list uint64
+	// Member index tracks the position of this node in the Heap it was last added to. It is kept up
+	// to date by the Heap. Since a node can be added to several heaps, it is only a hint that has
+	// to be validated before use.
+	index int
 	// Member graph tracks which graph this node is in. This will be set appripriately by the
 	// algorithm when adding and removing nodes to or from a heaped graph. This member is used only
-	// by the HeapedGraph.
+	// by the HeapedGraph and the BucketGraph.
 	graph GraphOps
 }
 
@@ -69,7 +102,9 @@ func NewNode(id string, cost int, numExpectedNeighbours int, payload interface{}
 		ID:          id,
 		Cost:        cost,
 		Payload:     payload,
-		connections: make(Graph, numExpectedNeighbours),
+		connections: make([]*Node, 0, numExpectedNeighbours),
+		connected:   make(Graph, numExpectedNeighbours),
+		serial:      atomic.AddUint64(&nodeSerial, 1),
 		trackedCost: startCost,
 		prev:        nil,
 	}
@@ -78,7 +113,10 @@ func NewNode(id string, cost int, numExpectedNeighbours int, payload interface{}
 
 // AddConnection adds a connection to a node. If the connection already exists, this is a no-op.
 func (n *Node) AddConnection(neighbour *Node) {
-	n.connections[neighbour] = graphVal
+	if !n.connectedTo(neighbour) {
+		n.connections = append(n.connections, neighbour)
+		n.connected[neighbour] = graphVal
+	}
 }
 
 // AddPairwiseConnection adds a connection to a node and from that node back to the receiver.. If
@@ -91,13 +129,59 @@ func (n *Node) AddPairwiseConnection(neighbour *Node) {
 // RemoveConnection removes a connection to a node. If the speicified node does not connect to this
 // node, this is a no-op.
 func (n *Node) RemoveConnection(neighbour *Node) {
-	delete(n.connections, neighbour)
+	if !n.connectedTo(neighbour) {
+		return
+	}
+	delete(n.connected, neighbour)
+	for idx, con := range n.connections {
+		if con == neighbour {
+			n.connections = append(n.connections[:idx], n.connections[idx+1:]...)
+			return
+		}
+	}
+}
+
+// TrackedCost provides the cost of the cheapest way from the start node to this node found so far
+// by the current search. It is meant for implementing your own GraphOps, e.g. to order the nodes.
+func (n *Node) TrackedCost() int {
+	return n.trackedCost
+}
+
+// UpdateIfBetter updates this node's best connection if reaching it via prev is cheaper than any
+// previously found connection. It takes the new possible best predecessor and the cost for reaching
+// that predecessor. It returns whether the node was updated. Use it to implement the method of the
+// same name for your own GraphOps.
+func (n *Node) UpdateIfBetter(prev *Node, prevCost int) bool {
+	newCost := prevCost + n.Cost
+	if newCost < n.trackedCost {
+		n.prev = prev
+		n.trackedCost = newCost
+		return true
+	}
+	return false
+}
+
+// Method reach prepares this node for being reached by the search with the given generation stamp.
+// If an earlier search reached this node, the state left behind by that search is discarded.
+func (n *Node) reach(generation uint64) {
+	if n.generation != generation {
+		n.generation = generation
+		n.prev = nil
+		n.trackedCost = defaultCost
+	}
+}
+
+// Method connectedTo determines whether there is a connection from this node to another one.
+func (n *Node) connectedTo(neighbour *Node) bool {
+	_, connected := n.connected[neighbour]
+	return connected
 }
 
 // ToString provides a nice string representation for this node. Not all members are used.
+// Connections are listed in the order they were added.
 func (n *Node) ToString() string {
 	conStrings := make([]string, 0, len(n.connections))
-	for con := range n.connections {
+	for _, con := range n.connections {
 		conStrings = append(conStrings, con.ID)
 	}
 	conString := strings.Join(conStrings, "', '")
@@ -111,3 +195,10 @@ func (n *Node) ToString() string {
 func (n Node) String() string {
 	return n.ToString()
 }
+
+// Function sortNodesByID sorts nodes according to their user-defined names.
+func sortNodesByID(nodes []*Node) {
+	sort.SliceStable(nodes, func(idx1, idx2 int) bool {
+		return nodes[idx1].ID < nodes[idx2].ID
+	})
+}
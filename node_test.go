@@ -80,3 +80,56 @@ func TestToString(t *testing.T) {
 	node1.RemoveConnection(node2)
 	assert.Equal(t, "{id: node1, cost: 1, con: ['']}", node1.ToString())
 }
+
+func TestNodeConnectionOrder(t *testing.T) {
+	nodes := []*Node{}
+	for _, id := range []string{"centre", "c", "a", "b"} {
+		node, err := NewNode(id, 1, 0, nil)
+		assert.NoError(t, err)
+		nodes = append(nodes, node)
+	}
+	centre := nodes[0]
+
+	// Connections are kept in the order they were added, not sorted in any way.
+	for _, node := range nodes[1:] {
+		centre.AddConnection(node)
+	}
+	centre.AddConnection(nodes[1])
+	assert.Equal(t, nodes[1:], centre.connections)
+	assert.Equal(t, "{id: centre, cost: 1, con: ['c', 'a', 'b']}", centre.ToString())
+
+	// Removing a connection keeps the order of the others. Adding it again puts it at the end.
+	centre.RemoveConnection(nodes[1])
+	assert.Equal(t, []*Node{nodes[2], nodes[3]}, centre.connections)
+	centre.AddConnection(nodes[1])
+	assert.Equal(t, []*Node{nodes[2], nodes[3], nodes[1]}, centre.connections)
+	assert.True(t, centre.connectedTo(nodes[1]))
+	assert.False(t, nodes[1].connectedTo(centre))
+}
+
+func TestNodeSerial(t *testing.T) {
+	node1, err := NewNode("node", 1, 0, nil)
+	assert.NoError(t, err)
+	node2, err := NewNode("node", 1, 0, nil)
+	assert.NoError(t, err)
+
+	assert.Less(t, node1.serial, node2.serial)
+}
+
+func TestNodeUpdateIfBetter(t *testing.T) {
+	node, err := NewNode("node", 2, 0, nil)
+	assert.NoError(t, err)
+	prev1, err := NewNode("prev1", 0, 0, nil)
+	assert.NoError(t, err)
+	prev2, err := NewNode("prev2", 0, 0, nil)
+	assert.NoError(t, err)
+	node.trackedCost = 10
+
+	assert.True(t, node.UpdateIfBetter(prev1, 5))
+	assert.Equal(t, prev1, node.prev)
+	assert.Equal(t, 7, node.TrackedCost())
+	// Equally expensive connections do not replace the existing one.
+	assert.False(t, node.UpdateIfBetter(prev2, 5))
+	assert.Equal(t, prev1, node.prev)
+	assert.Equal(t, 7, node.TrackedCost())
+}
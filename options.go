@@ -0,0 +1,120 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+// Option configures a single search performed by FindPath or FindReversePath. Options only
+// influence the search they are passed to. They never modify the graph. Obtain options via the
+// functions starting with "With", e.g. WithFilter.
+type Option func(*searchOptions)
+
+// Type searchOptions collects the settings of all options passed to a search.
+type searchOptions struct {
+	filter     func(*Node) bool
+	tieBreaker TieBreaker
+	trace      *Trace
+}
+
+// Function collectOptions applies all options in order. Later options override earlier ones.
+func collectOptions(opts []Option) searchOptions {
+	result := searchOptions{}
+	for _, opt := range opts {
+		opt(&result)
+	}
+	return result
+}
+
+// Method allows determines whether a node may be entered during the search.
+func (o *searchOptions) allows(node *Node) bool {
+	return o.filter == nil || o.filter(node)
+}
+
+// WithFilter restricts a search to nodes for which `filter` returns true. Neighbours failing the
+// predicate are skipped as if there were no connection to them, e.g. to keep ground units off water
+// tiles. The start node is never filtered. If the end node fails the predicate, no path will be
+// found. The filter is evaluated at most once per connection that is followed.
+func WithFilter(filter func(*Node) bool) Option {
+	return func(o *searchOptions) {
+		o.filter = filter
+	}
+}
+
+// WithTieBreaker breaks ties between nodes on the open list that have the same total cost using
+// `tieBreaker`. Without it, such ties are broken arbitrarily. Good tie-breaking, e.g. via
+// PreferHigherCost, can reduce the number of nodes expanded by orders of magnitude on grids with
+// many equally cheap paths. A tie-breaker that compares node IDs makes results reproducible.
+//
+// The open list has to implement TieBreakable. For FindPath, this means a HeapedGraph has to be
+// used. Otherwise, an error is returned. When calling FindReversePath directly, the tie-breaker is
+// set on the open list passed in and remains set afterwards.
+func WithTieBreaker(tieBreaker TieBreaker) Option {
+	return func(o *searchOptions) {
+		o.tieBreaker = tieBreaker
+	}
+}
+
+// WithTrace records what a search does in `trace`, e.g. to find out why a path took a detour. Any
+// previous content of `trace` is replaced. Use WriteDOT to visualise a trace.
+func WithTrace(trace *Trace) Option {
+	return func(o *searchOptions) {
+		o.trace = trace
+	}
+}
+
+// Trace records what a search did. Obtain one via WithTrace.
+type Trace struct {
+	// Expanded lists the nodes moved to the closed list in the order they were expanded.
+	Expanded []*Node
+	// Frontier lists the nodes still on the open list when the search ended, sorted by ID.
+	Frontier []*Node
+	// TrackedCosts holds, for each expanded node and each node on the frontier, the cost of the
+	// cheapest way from the start node found by the search.
+	TrackedCosts map[*Node]int
+}
+
+// Method start prepares a trace for a new search. It can be called for a nil trace.
+func (t *Trace) start() {
+	if t == nil {
+		return
+	}
+	t.Expanded = []*Node{}
+	t.Frontier = []*Node{}
+	t.TrackedCosts = map[*Node]int{}
+}
+
+// Method expand records that a node has been expanded. It can be called for a nil trace.
+func (t *Trace) expand(node *Node) {
+	if t == nil {
+		return
+	}
+	t.Expanded = append(t.Expanded, node)
+	t.TrackedCosts[node] = node.trackedCost
+}
+
+// Method finish records the nodes still on the open list. It can be called for a nil trace.
+func (t *Trace) finish(open GraphOps) error {
+	if t == nil {
+		return nil
+	}
+	err := open.Apply(func(node *Node) error {
+		t.Frontier = append(t.Frontier, node)
+		t.TrackedCosts[node] = node.trackedCost
+		return nil
+	})
+	sortNodesByID(t.Frontier)
+	return err
+}
@@ -0,0 +1,109 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fourNeighbours = [][2]int{
+	[2]int{-1, 0},
+	[2]int{0, -1},
+	[2]int{1, 0},
+	[2]int{0, 1},
+}
+
+func zeroHeuristic(_ *Node) int {
+	return 0
+}
+
+func TestCollectOptions(t *testing.T) {
+	options := collectOptions(nil)
+	assert.Nil(t, options.filter)
+	assert.True(t, options.allows(nil))
+
+	options = collectOptions([]Option{WithFilter(func(*Node) bool { return false })})
+	assert.NotNil(t, options.filter)
+	assert.False(t, options.allows(nil))
+}
+
+func TestFindPathWithFilter(t *testing.T) {
+	for _, graphType := range []string{"default", "heaped", "bucket"} {
+		graph, posToNode, err := CreateRegular2DGrid([2]int{3, 3}, fourNeighbours, graphType, 1)
+		assert.NoError(t, err)
+		start := posToNode[[2]int{0, 0}]
+		end := posToNode[[2]int{2, 0}]
+		// The filtered cells (1,0) and (1,1) block the middle column apart from the row y == 2, so
+		// the path has to go around them through that row. The filter is the only thing keeping the
+		// search off the direct way.
+		water := map[*Node]bool{
+			posToNode[[2]int{1, 0}]: true,
+			posToNode[[2]int{1, 1}]: true,
+		}
+		noWater := WithFilter(func(node *Node) bool { return !water[node] })
+
+		path, err := FindPath(graph, start, end, zeroHeuristic, noWater)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, len(path))
+		for _, node := range path {
+			assert.False(t, water[node])
+		}
+
+		// Without the filter, the direct way is taken again. The graph has not been modified.
+		path, err = FindPath(graph, start, end, zeroHeuristic)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(path))
+	}
+}
+
+func TestFindPathWithFilterEndExcluded(t *testing.T) {
+	graph, posToNode, err := CreateRegular2DGrid([2]int{3, 3}, fourNeighbours, "heaped", 1)
+	assert.NoError(t, err)
+	start := posToNode[[2]int{0, 0}]
+	end := posToNode[[2]int{2, 2}]
+
+	noEnd := WithFilter(func(node *Node) bool { return node != end })
+	_, err = FindPath(graph, start, end, zeroHeuristic, noEnd)
+	assert.Error(t, err)
+
+	// The start node is never filtered.
+	noStart := WithFilter(func(node *Node) bool { return node != start })
+	path, err := FindPath(graph, start, end, zeroHeuristic, noStart)
+	assert.NoError(t, err)
+	assert.Equal(t, start, path[0])
+}
+
+func TestFindPathWithTrace(t *testing.T) {
+	graph, nodes := setUpDiamond(t)
+	trace := Trace{}
+	_, err := FindPath(graph, nodes["s"], nodes["e"], zeroHeuristic, WithTrace(&trace))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*Node{nodes["s"], nodes["b"], nodes["e"]}, trace.Expanded)
+	assert.Equal(t, []*Node{nodes["a"]}, trace.Frontier)
+	expectedCosts := map[*Node]int{nodes["s"]: 0, nodes["a"]: 5, nodes["b"]: 1, nodes["e"]: 2}
+	assert.Equal(t, expectedCosts, trace.TrackedCosts)
+
+	// A new search replaces the previous trace.
+	_, err = FindPath(graph, nodes["s"], nodes["b"], zeroHeuristic, WithTrace(&trace))
+	assert.NoError(t, err)
+	assert.Equal(t, []*Node{nodes["s"], nodes["b"]}, trace.Expanded)
+	assert.Equal(t, []*Node{nodes["a"], nodes["e"]}, trace.Frontier)
+}
@@ -0,0 +1,94 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Path is a path together with information about its costs. Costs are determined the same way
+// FindPath does: moving onto a node costs that node's cost. Thus, the cost of the start node is
+// never included. Obtain one via FindDetailedPath or NewPath.
+type Path struct {
+	// Nodes contains the nodes of the path, starting with the start node.
+	Nodes []*Node
+	// Cost is the total cost of the path.
+	Cost int
+	// CumulativeCosts contains, for each node, the cost of getting there from the start node. The
+	// first entry is always zero and the last one equals Cost.
+	CumulativeCosts []int
+	// Estimates contains, for each node, the remaining cost estimated by the heuristic.
+	Estimates []int
+}
+
+// NewPath creates a Path from the nodes of a path, e.g. as returned by FindPath. The heuristic is
+// evaluated once for every node to fill in the estimates. Provide nil to set all estimates to
+// zero. The nodes are not checked for being connected.
+func NewPath(nodes []*Node, heuristic Heuristic) Path {
+	path := Path{
+		Nodes:           nodes,
+		CumulativeCosts: make([]int, 0, len(nodes)),
+		Estimates:       make([]int, 0, len(nodes)),
+	}
+	for idx, node := range nodes {
+		if idx > 0 {
+			path.Cost += node.Cost
+		}
+		path.CumulativeCosts = append(path.CumulativeCosts, path.Cost)
+		estimate := 0
+		if heuristic != nil {
+			estimate = heuristic(node)
+		}
+		path.Estimates = append(path.Estimates, estimate)
+	}
+	return path
+}
+
+// Hops determines the number of moves along the path, i.e. the number of nodes minus one. An empty
+// path has no hops.
+func (p Path) Hops() int {
+	if len(p.Nodes) == 0 {
+		return 0
+	}
+	return len(p.Nodes) - 1
+}
+
+// ToString provides a string representation of the path with one line per node. Each line contains
+// the node's ID, its cumulative cost, and its estimate.
+func (p Path) ToString() string {
+	lines := make([]string, 0, len(p.Nodes))
+	for idx, node := range p.Nodes {
+		lines = append(lines, fmt.Sprintf(
+			"%s: cost %d, estimate %d", node.ID, p.CumulativeCosts[idx], p.Estimates[idx],
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FindDetailedPath is the same as FindPath but returns a Path with information about costs instead
+// of only the nodes. The heuristic's estimates are included as well.
+func FindDetailedPath(
+	graph GraphOps, start, end *Node, heuristic Heuristic, opts ...Option,
+) (Path, error) {
+	nodes, err := FindPath(graph, start, end, heuristic, opts...)
+	if err != nil {
+		return NewPath([]*Node{}, nil), err
+	}
+	return NewPath(nodes, heuristic), nil
+}
@@ -0,0 +1,94 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPath(t *testing.T) {
+	_, nodeA, nodeB, nodeC := setUpChain(t)
+	heuristic := func(node *Node) int {
+		return map[*Node]int{nodeA: 4, nodeB: 3}[node]
+	}
+
+	path := NewPath([]*Node{nodeA, nodeB, nodeC}, heuristic)
+
+	assert.Equal(t, []*Node{nodeA, nodeB, nodeC}, path.Nodes)
+	// The cost of the start node is not included.
+	assert.Equal(t, 5, path.Cost)
+	assert.Equal(t, []int{0, 2, 5}, path.CumulativeCosts)
+	assert.Equal(t, []int{4, 3, 0}, path.Estimates)
+	assert.Equal(t, 2, path.Hops())
+	assert.Equal(
+		t, "a: cost 0, estimate 4\nb: cost 2, estimate 3\nc: cost 5, estimate 0", path.ToString(),
+	)
+
+	path = NewPath([]*Node{nodeB}, nil)
+	assert.Equal(t, 0, path.Cost)
+	assert.Equal(t, []int{0}, path.CumulativeCosts)
+	assert.Equal(t, []int{0}, path.Estimates)
+	assert.Equal(t, 0, path.Hops())
+
+	path = NewPath([]*Node{}, nil)
+	assert.Equal(t, 0, path.Cost)
+	assert.Equal(t, 0, path.Hops())
+	assert.Equal(t, "", path.ToString())
+}
+
+func TestPathCost(t *testing.T) {
+	_, line := setUpWaypointLine(t, 3)
+	line[0].Cost = 10
+	assert.Equal(t, 2, NewPath(line, nil).Cost)
+	assert.Equal(t, 0, NewPath(line[:1], nil).Cost)
+	assert.Equal(t, 0, NewPath([]*Node{}, nil).Cost)
+}
+
+func TestFindDetailedPath(t *testing.T) {
+	graph, posToNode := setUpVaryingGrid(t, 5, "default")
+	start, end := posToNode[[2]int{0, 0}], posToNode[[2]int{4, 4}]
+	heuristic, err := CreateConnectionsHeuristic2D(posToNode, [2]int{4, 4}, fourNeighbours, 0)
+	assert.NoError(t, err)
+
+	path, err := FindDetailedPath(graph, start, end, heuristic)
+	assert.NoError(t, err)
+
+	nodes, err := FindPath(graph, start, end, heuristic)
+	assert.NoError(t, err)
+	assert.Equal(t, NewPath(nodes, heuristic).Cost, path.Cost)
+	assert.Equal(t, start, path.Nodes[0])
+	assert.Equal(t, end, path.Nodes[path.Hops()])
+	assert.Equal(t, path.CumulativeCosts[len(path.CumulativeCosts)-1], path.Cost)
+	for idx, node := range path.Nodes {
+		assert.Equal(t, heuristic(node), path.Estimates[idx])
+		// The heuristic never over-estimates.
+		assert.LessOrEqual(t, path.Estimates[idx], path.Cost-path.CumulativeCosts[idx])
+	}
+}
+
+func TestFindDetailedPathFailure(t *testing.T) {
+	graph, nodeA, _, nodeC := setUpChain(t)
+
+	path, err := FindDetailedPath(graph, nodeC, nodeA, zeroHeuristic)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(path.Nodes))
+	assert.Equal(t, 0, path.Cost)
+}
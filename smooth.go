@@ -0,0 +1,219 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+)
+
+// Cell borders are at half-integer coordinates. Multiplying by this factor makes them integers.
+const cellScale = 2
+
+// PathSmoother2D post-processes paths on a regular 2D grid such as one created by
+// CreateRegular2DGrid. Paths found by FindPath step from node to node and thus contain many nodes
+// on straight lines as well as stair-step zig-zags. A smoother turns such a path into a few
+// waypoints that can be travelled in straight lines. Obtain one via NewPathSmoother2D.
+//
+// Smoothing only considers positions and connections, not costs. Thus, a smoothed path can be more
+// expensive than the original one on grids with varying costs. No nodes are modified.
+type PathSmoother2D struct {
+	posToNode map[[2]int]*Node
+	nodeToPos map[*Node][2]int
+}
+
+// Function invertPosMap2D determines the position of each node in a map from node positions to
+// node pointers. Each node may only be at a single position.
+func invertPosMap2D(posToNode map[[2]int]*Node) (map[*Node][2]int, error) {
+	nodeToPos := make(map[*Node][2]int, len(posToNode))
+	for pos, node := range posToNode {
+		if _, found := nodeToPos[node]; found {
+			return nil, fmt.Errorf("input sanitation: node %s at several positions", node.ID)
+		}
+		nodeToPos[node] = pos
+	}
+	return nodeToPos, nil
+}
+
+// NewPathSmoother2D creates a smoother for a grid. It takes a map from node positions to node
+// pointers as returned by CreateRegular2DGrid.
+func NewPathSmoother2D(posToNode map[[2]int]*Node) (*PathSmoother2D, error) {
+	nodeToPos, err := invertPosMap2D(posToNode)
+	if err != nil {
+		return nil, err
+	}
+	return &PathSmoother2D{posToNode: posToNode, nodeToPos: nodeToPos}, nil
+}
+
+// Method positions determines the positions of all nodes in a path.
+func (s *PathSmoother2D) positions(path []*Node) ([][2]int, error) {
+	result := make([][2]int, 0, len(path))
+	for _, node := range path {
+		pos, found := s.nodeToPos[node]
+		if !found {
+			return nil, fmt.Errorf("input sanitation: node %s not in grid", node.ID)
+		}
+		result = append(result, pos)
+	}
+	return result, nil
+}
+
+// Function gcd determines the greatest common divisor of the absolute values of two integers.
+func gcd(val1, val2 int) int {
+	val1, val2 = absInt(val1), absInt(val2)
+	for val2 != 0 {
+		val1, val2 = val2, val1%val2
+	}
+	return val1
+}
+
+// Function direction2D determines the direction from one position to another, reduced so that it
+// is the same for all positions on the same ray.
+func direction2D(from, to [2]int) [2]int {
+	diff := [2]int{to[0] - from[0], to[1] - from[1]}
+	if div := gcd(diff[0], diff[1]); div > 1 {
+		diff = [2]int{diff[0] / div, diff[1] / div}
+	}
+	return diff
+}
+
+// Simplify removes all nodes from a path that lie on a straight line between their predecessor
+// and successor in the path, i.e. where the path does not change direction. The first and last
+// nodes are always kept. The result can be travelled in straight lines between consecutive nodes
+// and follows exactly the same route as the original path.
+func (s *PathSmoother2D) Simplify(path []*Node) ([]*Node, error) {
+	positions, err := s.positions(path)
+	if err != nil {
+		return []*Node{}, err
+	}
+	if len(path) <= 1 {
+		return append([]*Node{}, path...), nil
+	}
+	result := []*Node{path[0]}
+	for idx := 1; idx < len(path)-1; idx++ {
+		before := direction2D(positions[idx-1], positions[idx])
+		after := direction2D(positions[idx], positions[idx+1])
+		if before != after {
+			result = append(result, path[idx])
+		}
+	}
+	return append(result, path[len(path)-1]), nil
+}
+
+// StringPull reduces a path to the nodes at which it has to change direction. Starting with the
+// first node, each node is connected in a straight line to the last node of the path that is in
+// line of sight. That one becomes the next waypoint. The first and last nodes are always kept.
+//
+// A straight line between two positions is in line of sight if every cell it touches is in the
+// grid and each step from cell to cell follows a connection. Cells in between must not be
+// obstacles, i.e. they must have connections. Where the line passes exactly through the corner of
+// two cells, both ways around the corner have to be possible. Thus, no corners are cut and nodes
+// without connections are never passed through.
+func (s *PathSmoother2D) StringPull(path []*Node) ([]*Node, error) {
+	positions, err := s.positions(path)
+	if err != nil {
+		return []*Node{}, err
+	}
+	if len(path) <= 1 {
+		return append([]*Node{}, path...), nil
+	}
+	result := []*Node{path[0]}
+	anchor := 0
+	for idx := 2; idx < len(path); idx++ {
+		if !s.lineOfSight(positions[anchor], positions[idx]) {
+			anchor = idx - 1
+			result = append(result, path[anchor])
+		}
+	}
+	return append(result, path[len(path)-1]), nil
+}
+
+// Method passable determines whether a position may be passed through.
+func (s *PathSmoother2D) passable(pos [2]int) bool {
+	node, found := s.posToNode[pos]
+	return found && len(node.connections) > 0
+}
+
+// Method connected determines whether there is a connection from one position to another.
+func (s *PathSmoother2D) connected(from, to [2]int) bool {
+	fromNode, fromFound := s.posToNode[from]
+	toNode, toFound := s.posToNode[to]
+	if !fromFound || !toFound {
+		return false
+	}
+	return fromNode.connectedTo(toNode)
+}
+
+// Method lineOfSight determines whether a straight line between the centres of two cells can be
+// travelled. The cells touched are visited in order, stepping either horizontally, vertically, or,
+// if the line passes exactly through a corner, diagonally.
+func (s *PathSmoother2D) lineOfSight(from, to [2]int) bool {
+	diffX, diffY := to[0]-from[0], to[1]-from[1]
+	stepX, stepY := sign(diffX), sign(diffY)
+	numX, numY := absInt(diffX), absInt(diffY)
+	curr := from
+	for doneX, doneY := 0, 0; doneX < numX || doneY < numY; {
+		var next [2]int
+		var possible bool
+		// Compare the distances to the next vertical and horizontal cell borders, scaled to avoid
+		// fractions.
+		decision := (cellScale*doneX+1)*numY - (cellScale*doneY+1)*numX
+		switch {
+		case decision == 0:
+			next = [2]int{curr[0] + stepX, curr[1] + stepY}
+			possible = s.aroundCorner(curr, next)
+			doneX++
+			doneY++
+		case decision < 0:
+			next = [2]int{curr[0] + stepX, curr[1]}
+			possible = s.connected(curr, next)
+			doneX++
+		default:
+			next = [2]int{curr[0], curr[1] + stepY}
+			possible = s.connected(curr, next)
+			doneY++
+		}
+		if !possible || (next != to && !s.passable(next)) {
+			return false
+		}
+		curr = next
+	}
+	return true
+}
+
+// Method aroundCorner determines whether a diagonal step is possible both ways around the corner,
+// i.e. horizontally first and vertically first.
+func (s *PathSmoother2D) aroundCorner(from, to [2]int) bool {
+	for _, side := range [][2]int{{to[0], from[1]}, {from[0], to[1]}} {
+		if !s.passable(side) || !s.connected(from, side) || !s.connected(side, to) {
+			return false
+		}
+	}
+	return true
+}
+
+// Function sign determines the sign of an integer as -1, 0, or 1.
+func sign(val int) int {
+	switch {
+	case val < 0:
+		return -1
+	case val > 0:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,154 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Create a grid with unit costs and a smoother for it.
+func setUpSmoother(t *testing.T, size int) (GraphOps, map[[2]int]*Node, *PathSmoother2D) {
+	graph, posToNode, err := CreateRegular2DGrid([2]int{size, size}, fourNeighbours, "default", 1)
+	assert.NoError(t, err)
+	smoother, err := NewPathSmoother2D(posToNode)
+	assert.NoError(t, err)
+	return graph, posToNode, smoother
+}
+
+// Turn positions into nodes.
+func nodesAt(posToNode map[[2]int]*Node, positions ...[2]int) []*Node {
+	result := []*Node{}
+	for _, pos := range positions {
+		result = append(result, posToNode[pos])
+	}
+	return result
+}
+
+func TestPathSmootherSimplify(t *testing.T) {
+	_, posToNode, smoother := setUpSmoother(t, 4)
+
+	path := nodesAt(posToNode, [2]int{0, 0}, [2]int{1, 0}, [2]int{2, 0}, [2]int{2, 1}, [2]int{2, 2})
+	simple, err := smoother.Simplify(path)
+	assert.NoError(t, err)
+	assert.Equal(t, nodesAt(posToNode, [2]int{0, 0}, [2]int{2, 0}, [2]int{2, 2}), simple)
+
+	// Zig-zags are kept.
+	path = nodesAt(posToNode, [2]int{0, 0}, [2]int{1, 0}, [2]int{1, 1}, [2]int{2, 1})
+	simple, err = smoother.Simplify(path)
+	assert.NoError(t, err)
+	assert.Equal(t, path, simple)
+
+	for _, path := range [][]*Node{{}, nodesAt(posToNode, [2]int{1, 1})} {
+		simple, err = smoother.Simplify(path)
+		assert.NoError(t, err)
+		assert.Equal(t, path, simple)
+	}
+}
+
+func TestPathSmootherStringPull(t *testing.T) {
+	_, posToNode, smoother := setUpSmoother(t, 4)
+
+	// A staircase becomes a single diagonal line.
+	path := nodesAt(posToNode,
+		[2]int{0, 0}, [2]int{1, 0}, [2]int{1, 1}, [2]int{2, 1},
+		[2]int{2, 2}, [2]int{3, 2}, [2]int{3, 3},
+	)
+	pulled, err := smoother.StringPull(path)
+	assert.NoError(t, err)
+	assert.Equal(t, nodesAt(posToNode, [2]int{0, 0}, [2]int{3, 3}), pulled)
+
+	// Lines that do not pass through corners exactly.
+	path = nodesAt(posToNode, [2]int{0, 0}, [2]int{1, 0}, [2]int{2, 0}, [2]int{2, 1})
+	pulled, err = smoother.StringPull(path)
+	assert.NoError(t, err)
+	assert.Equal(t, nodesAt(posToNode, [2]int{0, 0}, [2]int{2, 1}), pulled)
+
+	for _, path := range [][]*Node{{}, nodesAt(posToNode, [2]int{1, 1})} {
+		pulled, err = smoother.StringPull(path)
+		assert.NoError(t, err)
+		assert.Equal(t, path, pulled)
+	}
+}
+
+func TestPathSmootherStringPullObstacles(t *testing.T) {
+	_, posToNode, smoother := setUpSmoother(t, 4)
+	isolate(posToNode[[2]int{1, 1}])
+
+	path := nodesAt(posToNode, [2]int{0, 0}, [2]int{1, 0}, [2]int{2, 0}, [2]int{2, 1}, [2]int{2, 2})
+	pulled, err := smoother.StringPull(path)
+	assert.NoError(t, err)
+	assert.Equal(t, nodesAt(posToNode, [2]int{0, 0}, [2]int{2, 0}, [2]int{2, 2}), pulled)
+
+	// Corners next to obstacles are not cut.
+	path = nodesAt(posToNode, [2]int{1, 0}, [2]int{2, 0}, [2]int{2, 1})
+	pulled, err = smoother.StringPull(path)
+	assert.NoError(t, err)
+	assert.Equal(t, path, pulled)
+
+	// Walls built by removing connections are respected, too.
+	disconnect(posToNode[[2]int{2, 2}], posToNode[[2]int{3, 2}])
+	path = nodesAt(posToNode, [2]int{2, 2}, [2]int{2, 3}, [2]int{3, 3}, [2]int{3, 2}, [2]int{3, 1})
+	pulled, err = smoother.StringPull(path)
+	assert.NoError(t, err)
+	// Without the wall, the first waypoint after the start would be at (3, 3).
+	assert.Equal(
+		t, nodesAt(posToNode, [2]int{2, 2}, [2]int{2, 3}, [2]int{3, 3}, [2]int{3, 1}), pulled,
+	)
+}
+
+func TestPathSmootherFoundPath(t *testing.T) {
+	graph, posToNode, smoother := setUpSmoother(t, 10)
+	for y := 0; y < 8; y++ {
+		isolate(posToNode[[2]int{5, y}])
+	}
+	start, end := posToNode[[2]int{0, 0}], posToNode[[2]int{9, 0}]
+
+	path, err := FindPath(graph, start, end, zeroHeuristic)
+	assert.NoError(t, err)
+	pulled, err := smoother.StringPull(path)
+	assert.NoError(t, err)
+
+	assert.Less(t, len(pulled), len(path))
+	assert.Equal(t, start, pulled[0])
+	assert.Equal(t, end, pulled[len(pulled)-1])
+	for idx := 1; idx < len(pulled); idx++ {
+		from, to := smoother.nodeToPos[pulled[idx-1]], smoother.nodeToPos[pulled[idx]]
+		assert.True(t, smoother.lineOfSight(from, to), "%v -> %v", from, to)
+	}
+	// The waypoints are a subset of the original path.
+	for _, node := range pulled {
+		assert.Contains(t, path, node)
+	}
+}
+
+func TestPathSmootherFailure(t *testing.T) {
+	_, posToNode, smoother := setUpSmoother(t, 2)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+
+	_, err = smoother.Simplify([]*Node{outside})
+	assert.Error(t, err)
+	_, err = smoother.StringPull([]*Node{posToNode[[2]int{0, 0}], outside})
+	assert.Error(t, err)
+
+	posToNode[[2]int{5, 5}] = posToNode[[2]int{0, 0}]
+	_, err = NewPathSmoother2D(posToNode)
+	assert.Error(t, err)
+}
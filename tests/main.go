@@ -18,7 +18,8 @@ const (
 	gridSize       = 200
 	numNeigh       = 4
 	expectedLength = 417
-	expectedCost   = 827
+	// The cost of the start node is not included.
+	expectedCost = 822
 )
 
 var quiet = os.Getenv("QUIET") == "1"
@@ -84,6 +85,8 @@ func main() {
 	switch os.Getenv("GRAPH_TYPE") {
 	case "HEAPED":
 		graph = astar.NewHeapedGraph(gridSize * gridSize)
+	case "BUCKET":
+		graph = astar.NewBucketGraph(gridSize * gridSize)
 	case "MAPPED":
 		graph = astar.NewGraph(gridSize * gridSize)
 	default:
@@ -98,7 +101,7 @@ func main() {
 
 	startTime := time.Now()
 	// Run the test.
-	path, err := astar.FindPath(graph, start, end, heuristic.Heuristic(0))
+	path, err := astar.FindDetailedPath(graph, start, end, heuristic.Heuristic(0))
 	if err != nil {
 		log.Fatal(err.Error())
 	}
@@ -106,23 +109,23 @@ func main() {
 
 	logStr("path is")
 
-	cost := 0
-	for _, node := range path {
+	for _, node := range path.Nodes {
 		logStr(node.ToString())
-		cost += node.Cost
 	}
 
-	logStr(fmt.Sprintf("total cost is %d", cost))
+	logStr(fmt.Sprintf("total cost is %d", path.Cost))
 
-	if len(path) != expectedLength {
+	if len(path.Nodes) != expectedLength {
 		log.Fatalf(
 			"path does not have the expected length (want: %d, has: %d)",
-			expectedLength, len(path),
+			expectedLength, len(path.Nodes),
 		)
 	}
 
-	if cost != expectedCost {
-		log.Fatalf("path does not have the expected cost (want: %d, has: %d)", expectedCost, cost)
+	if path.Cost != expectedCost {
+		log.Fatalf(
+			"path does not have the expected cost (want: %d, has: %d)", expectedCost, path.Cost,
+		)
 	}
 
 	logStr("obtained path")
@@ -0,0 +1,94 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+// TieInfo describes a node on the open list. It is passed to a TieBreaker.
+type TieInfo struct {
+	// Node is the node on the open list.
+	Node *Node
+	// TrackedCost is the cost of the cheapest way from the start node to Node found so far.
+	TrackedCost int
+	// Estimate is the heuristic's estimate for the remaining cost from Node to the end node.
+	Estimate int
+	// Seq describes when Node was added to the open list. Nodes added later have higher values.
+	Seq int
+}
+
+// TieBreaker decides which of two nodes on the open list to expand first if both have the same
+// total cost, i.e. the same sum of tracked cost and estimate. It returns true if the first one
+// shall be expanded before the second one. It is never called for nodes with different total
+// costs. Thus, it cannot lead to sub-optimal paths. Use it via WithTieBreaker.
+//
+// A tie-breaker has to be a strict weak ordering, just like the less function for sort.Slice. Use
+// one of the predefined ones, e.g. PreferHigherCost, or write your own.
+type TieBreaker func(first, second TieInfo) bool
+
+// PreferHigherCost expands nodes that are farther away from the start first. On grids with many
+// equally cheap paths, this avoids exploring all of them and usually reduces the number of nodes
+// expanded tremendously. For ties in the total cost, this is the same as PreferLowerEstimate.
+func PreferHigherCost(first, second TieInfo) bool {
+	return first.TrackedCost > second.TrackedCost
+}
+
+// PreferLowerEstimate expands nodes that are estimated to be closer to the end first. For ties in
+// the total cost, this is the same as PreferHigherCost.
+func PreferLowerEstimate(first, second TieInfo) bool {
+	return first.Estimate < second.Estimate
+}
+
+// PreferFIFO expands nodes that were added to the open list earlier first.
+func PreferFIFO(first, second TieInfo) bool {
+	return first.Seq < second.Seq
+}
+
+// PreferLIFO expands nodes that were added to the open list later first.
+func PreferLIFO(first, second TieInfo) bool {
+	return first.Seq > second.Seq
+}
+
+// TieBreakable is implemented by graphs that support breaking ties when used as open list. The
+// HeapedGraph is one of them.
+type TieBreakable interface {
+	// SetTieBreaker sets the tie-breaker to use from now on. Provide nil to break ties arbitrarily.
+	SetTieBreaker(tieBreaker TieBreaker)
+}
+
+// Type tieBreakingHeap orders a Heap by total cost first and uses a tie-breaker for equal total
+// costs.
+type tieBreakingHeap struct {
+	*Heap
+	tieBreaker TieBreaker
+}
+
+// Method tieInfo describes a heap element for a tie-breaker.
+func (e *HeapElement) tieInfo() TieInfo {
+	return TieInfo{Node: e.Node, TrackedCost: e.Node.trackedCost, Estimate: e.Estimate, Seq: e.seq}
+}
+
+// Less determines whether one value is smaller than another one, breaking ties via the
+// tie-breaker. This is needed for Go's heap interface.
+func (h *tieBreakingHeap) Less(i, j int) bool {
+	iElem := &(*h.Heap)[i]
+	jElem := &(*h.Heap)[j]
+	iTotal := iElem.Node.trackedCost + iElem.Estimate
+	jTotal := jElem.Node.trackedCost + jElem.Estimate
+	if iTotal != jTotal {
+		return iTotal < jTotal
+	}
+	return h.tieBreaker(iElem.tieInfo(), jElem.tieInfo())
+}
@@ -0,0 +1,131 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredefinedTieBreakers(t *testing.T) {
+	early := TieInfo{TrackedCost: 3, Estimate: 1, Seq: 0}
+	late := TieInfo{TrackedCost: 1, Estimate: 3, Seq: 1}
+
+	assert.True(t, PreferHigherCost(early, late))
+	assert.False(t, PreferHigherCost(late, early))
+	assert.True(t, PreferLowerEstimate(early, late))
+	assert.False(t, PreferLowerEstimate(late, early))
+	assert.True(t, PreferFIFO(early, late))
+	assert.False(t, PreferFIFO(late, early))
+	assert.True(t, PreferLIFO(late, early))
+	assert.False(t, PreferLIFO(early, late))
+}
+
+func TestHeapedGraphSetTieBreaker(t *testing.T) {
+	graph := NewHeapedGraph(0).(*HeapedGraph)
+	nodes := []*Node{}
+	// All nodes have the same total cost of 4 but are added in a different order than their costs
+	// suggest.
+	for idx, trackedCost := range []int{2, 0, 3, 1} {
+		node, err := NewNode(fmt.Sprintf("node%d", idx), 0, 0, nil)
+		assert.NoError(t, err)
+		node.trackedCost = trackedCost
+		graph.Push(node, 4-trackedCost)
+		nodes = append(nodes, node)
+	}
+	// A cheaper node is always popped first, independent of the tie-breaker.
+	cheap, err := NewNode("cheap", 0, 0, nil)
+	assert.NoError(t, err)
+
+	popAll := func(tieBreaker TieBreaker) []*Node {
+		graph.SetTieBreaker(tieBreaker)
+		graph.Push(cheap, 0)
+		assert.Equal(t, cheap, graph.PopCheapest())
+		popped := []*Node{}
+		for graph.Len() > 0 {
+			popped = append(popped, graph.PopCheapest())
+		}
+		// Put the nodes back in the original order.
+		graph.nextSeq = 0
+		for _, node := range nodes {
+			graph.Push(node, 4-node.trackedCost)
+		}
+		return popped
+	}
+
+	assert.Equal(t, nodes, popAll(PreferFIFO))
+	assert.Equal(t, []*Node{nodes[3], nodes[2], nodes[1], nodes[0]}, popAll(PreferLIFO))
+	expected := []*Node{nodes[2], nodes[0], nodes[3], nodes[1]}
+	assert.Equal(t, expected, popAll(PreferHigherCost))
+	assert.Equal(t, expected, popAll(PreferLowerEstimate))
+	// Ties are broken arbitrarily without a tie-breaker.
+	assert.ElementsMatch(t, nodes, popAll(nil))
+}
+
+func TestFindPathWithTieBreaker(t *testing.T) {
+	size := 30
+	graph, posToNode, err := CreateRegular2DGrid([2]int{size, size}, fourNeighbours, "heaped", 1)
+	assert.NoError(t, err)
+	start, end := posToNode[[2]int{0, 0}], posToNode[[2]int{size - 1, size - 1}]
+	heuristic, err := CreateConnectionsHeuristic2D(
+		posToNode, [2]int{size - 1, size - 1}, fourNeighbours, 0,
+	)
+	assert.NoError(t, err)
+
+	// Count how often the heuristic is evaluated. That happens once per node added to any list.
+	findPath := func(tieBreaker TieBreaker) ([]*Node, int) {
+		calls := 0
+		countingHeuristic := func(node *Node) int {
+			calls++
+			return heuristic(node)
+		}
+		path, err := FindPath(graph, start, end, countingHeuristic, WithTieBreaker(tieBreaker))
+		assert.NoError(t, err)
+		assert.Equal(t, 2*(size-1), NewPath(path, nil).Cost)
+		return path, calls
+	}
+
+	_, callsFIFO := findPath(PreferFIFO)
+	_, callsHigherCost := findPath(PreferHigherCost)
+	// Every path is equally cheap. Going straight for the end saves a lot of effort.
+	assert.Greater(t, callsFIFO, 5*callsHigherCost)
+
+	// Comparing IDs makes results reproducible.
+	byID := func(first, second TieInfo) bool {
+		return first.Node.ID < second.Node.ID
+	}
+	expected, _ := findPath(byID)
+	for idx := 0; idx < 5; idx++ {
+		path, _ := findPath(byID)
+		assert.Equal(t, expected, path)
+	}
+}
+
+func TestFindPathWithTieBreakerFailure(t *testing.T) {
+	graph, nodeA, _, nodeC := setUpChain(t)
+
+	_, err := FindPath(graph, nodeA, nodeC, zeroHeuristic, WithTieBreaker(PreferFIFO))
+	assert.Error(t, err)
+
+	open, closed := NewGraph(0), NewGraph(0)
+	open.Push(nodeA, 0)
+	err = FindReversePath(open, closed, nodeC, zeroHeuristic, WithTieBreaker(PreferFIFO))
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Overestimate describes a node for which a heuristic estimates a higher remaining cost than the
+// actual cost of the cheapest path to the end node.
+type Overestimate struct {
+	Node     *Node
+	Estimate int
+	Exact    int
+}
+
+// Inconsistency describes a connection along which a heuristic's estimate drops by more than the
+// cost of following the connection, i.e. estimate(From) > To.Cost + estimate(To).
+type Inconsistency struct {
+	From         *Node
+	To           *Node
+	EstimateFrom int
+	EstimateTo   int
+}
+
+// HeuristicReport is the result of CheckHeuristic. A heuristic is admissible if it never
+// over-estimates. In that case, FindPath is guaranteed to find the cheapest path. It is consistent
+// if it never drops by more than the cost of a connection. Every consistent heuristic that
+// estimates zero for the end node is also admissible.
+type HeuristicReport struct {
+	// Overestimates lists all nodes for which the heuristic over-estimates. They are sorted by node
+	// ID.
+	Overestimates []Overestimate
+	// Inconsistencies lists all connections along which the heuristic is inconsistent. They are
+	// sorted by the IDs of the nodes they connect.
+	Inconsistencies []Inconsistency
+}
+
+// Admissible determines whether no over-estimates have been found.
+func (r HeuristicReport) Admissible() bool {
+	return len(r.Overestimates) == 0
+}
+
+// Consistent determines whether no inconsistencies have been found.
+func (r HeuristicReport) Consistent() bool {
+	return len(r.Inconsistencies) == 0
+}
+
+// ToString provides a string representation of the report with one line per problem found.
+func (r HeuristicReport) ToString() string {
+	lines := make([]string, 0, len(r.Overestimates)+len(r.Inconsistencies))
+	for _, over := range r.Overestimates {
+		lines = append(lines, fmt.Sprintf(
+			"over-estimate at %s: estimate %d, exact %d", over.Node.ID, over.Estimate, over.Exact,
+		))
+	}
+	for _, inc := range r.Inconsistencies {
+		lines = append(lines, fmt.Sprintf(
+			"inconsistency from %s to %s: estimates %d and %d, cost %d",
+			inc.From.ID, inc.To.ID, inc.EstimateFrom, inc.EstimateTo, inc.To.Cost,
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckHeuristic validates a heuristic for a graph and an end node. The exact cost for reaching the
+// end node is computed for every node in the graph. Every node for which the heuristic estimates a
+// higher cost is reported, as is every connection between nodes in the graph along which the
+// heuristic is inconsistent. Nodes from which the end node cannot be reached are never reported as
+// over-estimated.
+//
+// This is meant to catch heuristics that silently cause sub-optimal paths, e.g. in tests. The
+// effort is that of a full search from the end node, so don't use it for every search. The nodes
+// are not modified.
+func CheckHeuristic(graph GraphOps, end *Node, heuristic Heuristic) (HeuristicReport, error) {
+	report := HeuristicReport{}
+	if !graph.Has(end) {
+		return report, fmt.Errorf("input sanitation: end node not in graph")
+	}
+	expand, err := reverseExpand(graph)
+	if err != nil {
+		return report, err
+	}
+	exact, _ := dijkstra(end, expand, nil)
+
+	// Evaluate the heuristic only once per node.
+	estimates := map[*Node]int{}
+	err = graph.Apply(func(node *Node) error {
+		estimates[node] = heuristic(node)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	err = graph.Apply(func(node *Node) error {
+		estimate := estimates[node]
+		if cost, found := exact[node]; found && estimate > cost {
+			report.Overestimates = append(
+				report.Overestimates,
+				Overestimate{Node: node, Estimate: estimate, Exact: cost},
+			)
+		}
+		for _, neigh := range node.connections {
+			neighEstimate, inGraph := estimates[neigh]
+			if inGraph && estimate > neigh.Cost+neighEstimate {
+				report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+					From: node, To: neigh, EstimateFrom: estimate, EstimateTo: neighEstimate,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	sort.SliceStable(report.Overestimates, func(idx1, idx2 int) bool {
+		return report.Overestimates[idx1].Node.ID < report.Overestimates[idx2].Node.ID
+	})
+	sort.SliceStable(report.Inconsistencies, func(idx1, idx2 int) bool {
+		inc1, inc2 := report.Inconsistencies[idx1], report.Inconsistencies[idx2]
+		if inc1.From.ID == inc2.From.ID {
+			return inc1.To.ID < inc2.To.ID
+		}
+		return inc1.From.ID < inc2.From.ID
+	})
+	return report, nil
+}
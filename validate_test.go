@@ -0,0 +1,105 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckHeuristicAdmissibleAndConsistent(t *testing.T) {
+	graph, posToNode, err := CreateRegular2DGrid([2]int{5, 5}, fourNeighbours, "default", 1)
+	assert.NoError(t, err)
+	end := [2]int{4, 4}
+	heuristic := ConstantHeuristic{}
+	for pos, node := range posToNode {
+		err := heuristic.AddNode(node, (end[0]-pos[0])+(end[1]-pos[1]))
+		assert.NoError(t, err)
+	}
+
+	report, err := CheckHeuristic(graph, posToNode[end], heuristic.Heuristic(0))
+
+	assert.NoError(t, err)
+	assert.True(t, report.Admissible())
+	assert.True(t, report.Consistent())
+	assert.Empty(t, report.ToString())
+}
+
+func TestCheckHeuristicZeroCostGrid(t *testing.T) {
+	// With zero costs everywhere, every path is free. Any positive estimate over-estimates.
+	graph, posToNode, err := CreateRegular2DGrid([2]int{3, 1}, fourNeighbours, "heaped", 0)
+	assert.NoError(t, err)
+	end := [2]int{2, 0}
+	heuristic, err := CreateConstantHeuristic2D(posToNode, end, 0)
+	assert.NoError(t, err)
+
+	report, err := CheckHeuristic(graph, posToNode[end], heuristic)
+
+	assert.NoError(t, err)
+	assert.False(t, report.Admissible())
+	assert.False(t, report.Consistent())
+	assert.Equal(t, []Overestimate{
+		Overestimate{Node: posToNode[[2]int{0, 0}], Estimate: 2, Exact: 0},
+		Overestimate{Node: posToNode[[2]int{1, 0}], Estimate: 1, Exact: 0},
+	}, report.Overestimates)
+	assert.Equal(t, []Inconsistency{
+		Inconsistency{
+			From: posToNode[[2]int{0, 0}], To: posToNode[[2]int{1, 0}],
+			EstimateFrom: 2, EstimateTo: 1,
+		},
+		Inconsistency{
+			From: posToNode[[2]int{1, 0}], To: posToNode[[2]int{2, 0}],
+			EstimateFrom: 1, EstimateTo: 0,
+		},
+	}, report.Inconsistencies)
+	expected := "over-estimate at x:0,y:0: estimate 2, exact 0\n" +
+		"over-estimate at x:1,y:0: estimate 1, exact 0\n" +
+		"inconsistency from x:0,y:0 to x:1,y:0: estimates 2 and 1, cost 0\n" +
+		"inconsistency from x:1,y:0 to x:2,y:0: estimates 1 and 0, cost 0"
+	assert.Equal(t, expected, report.ToString())
+}
+
+func TestCheckHeuristicUnreachableNotReported(t *testing.T) {
+	graph, nodeA, _, nodeC := setUpChain(t)
+
+	// Nothing can reach node a, so its estimate is irrelevant for admissibility.
+	heuristic := func(node *Node) int {
+		if node == nodeC {
+			return 1000
+		}
+		return 0
+	}
+	report, err := CheckHeuristic(graph, nodeA, heuristic)
+
+	assert.NoError(t, err)
+	assert.True(t, report.Admissible())
+	assert.True(t, report.Consistent())
+}
+
+func TestCheckHeuristicFailure(t *testing.T) {
+	graph, _, _, _ := setUpChain(t)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+
+	_, err = CheckHeuristic(graph, outside, zeroHeuristic)
+	assert.Error(t, err)
+
+	_, err = CheckHeuristic(&failingApplyGraph{}, outside, zeroHeuristic)
+	assert.Error(t, err)
+}
@@ -0,0 +1,218 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxOptimalWaypoints is the maximum number of waypoints FindOptimalWaypointPath accepts. The
+// effort for finding the best order grows exponentially with the number of waypoints.
+const MaxOptimalWaypoints = 16
+
+// Function findLeg finds the path for one leg between two stops. A leg from a node to itself is a
+// path containing only that node.
+func findLeg(
+	graph GraphOps, from, to *Node, heuristics HeuristicFactory, opts []Option,
+) ([]*Node, error) {
+	if from == to {
+		if !graph.Has(from) {
+			return []*Node{}, fmt.Errorf("input sanitation: node not in graph")
+		}
+		return []*Node{from}, nil
+	}
+	heuristic := func(*Node) int { return 0 }
+	if heuristics != nil {
+		heuristic = heuristics(to)
+	}
+	return FindPath(graph, from, to, heuristic, opts...)
+}
+
+// Function joinLegs concatenates the paths of several legs. The node joining two legs is only
+// contained once in the result.
+func joinLegs(legs [][]*Node) []*Node {
+	path := []*Node{}
+	for idx, leg := range legs {
+		if idx == 0 {
+			path = append(path, leg...)
+		} else {
+			path = append(path, leg[1:]...)
+		}
+	}
+	return path
+}
+
+// FindWaypointPath finds a path that starts at the first of the given stops, visits all other
+// stops in the given order, and ends at the last one. The path between each pair of consecutive
+// stops, a leg, is the cheapest one as found by FindPath. The legs are concatenated so that each
+// node joining two legs is contained only once.
+//
+// Since every leg has a different end node, a heuristic is needed per leg. It is obtained from
+// `heuristics` for the end node of each leg. Provide nil to use a heuristic that always estimates
+// zero. The options are passed on to FindPath for every leg.
+func FindWaypointPath(
+	graph GraphOps, stops []*Node, heuristics HeuristicFactory, opts ...Option,
+) ([]*Node, error) {
+	if len(stops) < 2 {
+		return []*Node{}, fmt.Errorf("input sanitation: need at least two stops")
+	}
+	legs := make([][]*Node, 0, len(stops)-1)
+	for idx := 1; idx < len(stops); idx++ {
+		leg, err := findLeg(graph, stops[idx-1], stops[idx], heuristics, opts)
+		if err != nil {
+			return []*Node{}, fmt.Errorf("error for leg %d: %s", idx, err.Error())
+		}
+		legs = append(legs, leg)
+	}
+	return joinLegs(legs), nil
+}
+
+// FindOptimalWaypointPath finds the cheapest path from start to end that visits all waypoints in
+// any order. This is a small travelling salesman problem. First, the legs between all pairs of
+// stops are determined via FindPath. Then, the cheapest order is determined exactly. At most
+// MaxOptimalWaypoints waypoints are supported since the effort grows exponentially with their
+// number. Heuristics and options are treated exactly as by FindWaypointPath.
+//
+//nolint:funlen
+func FindOptimalWaypointPath(
+	graph GraphOps, start, end *Node, waypoints []*Node, heuristics HeuristicFactory,
+	opts ...Option,
+) ([]*Node, error) {
+	numWaypoints := len(waypoints)
+	if numWaypoints > MaxOptimalWaypoints {
+		return []*Node{}, fmt.Errorf(
+			"input sanitation: at most %d waypoints supported, got %d",
+			MaxOptimalWaypoints, numWaypoints,
+		)
+	}
+	if numWaypoints == 0 {
+		return FindWaypointPath(graph, []*Node{start, end}, heuristics, opts...)
+	}
+
+	// Stop 0 is the start, stops 1 to numWaypoints are the waypoints, and the last one is the end.
+	stops := make([]*Node, 0, numWaypoints+2)
+	stops = append(stops, start)
+	stops = append(stops, waypoints...)
+	stops = append(stops, end)
+	endIdx := len(stops) - 1
+
+	// Determine all legs that might be needed and their costs. A nil leg with a negative cost
+	// means there is no connection.
+	legs := make([][][]*Node, len(stops))
+	legCosts := make([][]int, len(stops))
+	for from := range stops {
+		legs[from] = make([][]*Node, len(stops))
+		legCosts[from] = make([]int, len(stops))
+		for to := range legCosts[from] {
+			legCosts[from][to] = -1
+		}
+		if from == endIdx {
+			continue
+		}
+		for to := 1; to < len(stops); to++ {
+			if from == to {
+				continue
+			}
+			leg, err := findLeg(graph, stops[from], stops[to], heuristics, opts)
+			if errors.Is(err, errNoPath) {
+				continue
+			}
+			if err != nil {
+				return []*Node{}, fmt.Errorf("error for leg %d->%d: %s", from, to, err.Error())
+			}
+			legs[from][to] = leg
+			legCosts[from][to] = NewPath(leg, nil).Cost
+		}
+	}
+
+	// Use dynamic programming to find the best order. The value of best[visited][last] is the
+	// cost of the cheapest way from the start via all waypoints in the set `visited` that ends at
+	// waypoint `last`. A waypoint with index idx is in the set if bit idx is set. Negative values
+	// mean that there is no such way.
+	numSets := 1 << uint(numWaypoints)
+	best := make([][]int, numSets)
+	prev := make([][]int, numSets)
+	for visited := range best {
+		best[visited] = make([]int, numWaypoints)
+		prev[visited] = make([]int, numWaypoints)
+		for last := range best[visited] {
+			best[visited][last] = -1
+		}
+	}
+	for last := 0; last < numWaypoints; last++ {
+		if legCost := legCosts[0][last+1]; legCost >= 0 {
+			best[1<<uint(last)][last] = legCost
+			prev[1<<uint(last)][last] = -1
+		}
+	}
+	for visited := 1; visited < numSets; visited++ {
+		for last := 0; last < numWaypoints; last++ {
+			cost := best[visited][last]
+			if cost < 0 {
+				continue
+			}
+			for next := 0; next < numWaypoints; next++ {
+				legCost := legCosts[last+1][next+1]
+				if visited&(1<<uint(next)) != 0 || legCost < 0 {
+					continue
+				}
+				nextVisited := visited | 1<<uint(next)
+				nextCost := cost + legCost
+				if best[nextVisited][next] < 0 || nextCost < best[nextVisited][next] {
+					best[nextVisited][next] = nextCost
+					prev[nextVisited][next] = last
+				}
+			}
+		}
+	}
+
+	// Find the best last waypoint before going to the end.
+	all := numSets - 1
+	bestLast, bestCost := -1, 0
+	for last := 0; last < numWaypoints; last++ {
+		legCost := legCosts[last+1][endIdx]
+		if best[all][last] < 0 || legCost < 0 {
+			continue
+		}
+		cost := best[all][last] + legCost
+		if bestLast < 0 || cost < bestCost {
+			bestLast, bestCost = last, cost
+		}
+	}
+	if bestLast < 0 {
+		return []*Node{}, fmt.Errorf("no path found: %w", errNoPath)
+	}
+
+	// Follow the chosen order backwards and collect the legs on the way.
+	revLegs := [][]*Node{legs[bestLast+1][endIdx]}
+	for visited, last := all, bestLast; last >= 0; {
+		before := prev[visited][last]
+		if before < 0 {
+			revLegs = append(revLegs, legs[0][last+1])
+		} else {
+			revLegs = append(revLegs, legs[before+1][last+1])
+		}
+		visited, last = visited&^(1<<uint(last)), before
+	}
+	orderedLegs := make([][]*Node, 0, len(revLegs))
+	for idx := len(revLegs) - 1; idx >= 0; idx-- {
+		orderedLegs = append(orderedLegs, revLegs[idx])
+	}
+	return joinLegs(orderedLegs), nil
+}
@@ -0,0 +1,144 @@
+/* An implementation of the A* algorithm in plain Golang.
+Copyright (C) 2021  Torsten Sachse
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package astar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Create a line of nodes connected pairwise, all with a cost of one.
+func setUpWaypointLine(t *testing.T, length int) (GraphOps, []*Node) {
+	graph, posToNode, err := CreateRegular2DGrid(
+		[2]int{length, 1}, [][2]int{[2]int{-1, 0}, [2]int{1, 0}}, "heaped", 1,
+	)
+	assert.NoError(t, err)
+	line := make([]*Node, 0, length)
+	for x := 0; x < length; x++ {
+		line = append(line, posToNode[[2]int{x, 0}])
+	}
+	return graph, line
+}
+
+func TestFindWaypointPathKeepsOrder(t *testing.T) {
+	graph, line := setUpWaypointLine(t, 5)
+
+	path, err := FindWaypointPath(graph, []*Node{line[0], line[4], line[2]}, nil)
+
+	assert.NoError(t, err)
+	expected := []*Node{line[0], line[1], line[2], line[3], line[4], line[3], line[2]}
+	assert.Equal(t, expected, path)
+}
+
+func TestFindWaypointPathRepeatedStop(t *testing.T) {
+	graph, line := setUpWaypointLine(t, 3)
+
+	path, err := FindWaypointPath(graph, []*Node{line[0], line[1], line[1], line[2]}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, line, path)
+}
+
+func TestFindWaypointPathHeuristicPerLeg(t *testing.T) {
+	graph, line := setUpWaypointLine(t, 3)
+
+	requested := []*Node{}
+	heuristics := func(end *Node) Heuristic {
+		requested = append(requested, end)
+		return zeroHeuristic
+	}
+	_, err := FindWaypointPath(graph, line, heuristics)
+
+	assert.NoError(t, err)
+	assert.Equal(t, line[1:], requested)
+}
+
+func TestFindWaypointPathFailure(t *testing.T) {
+	graph, line := setUpWaypointLine(t, 3)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+
+	_, err = FindWaypointPath(graph, []*Node{line[0]}, nil)
+	assert.Error(t, err)
+	_, err = FindWaypointPath(graph, []*Node{line[0], outside}, nil)
+	assert.Error(t, err)
+	_, err = FindWaypointPath(graph, []*Node{outside, outside}, nil)
+	assert.Error(t, err)
+}
+
+func TestFindOptimalWaypointPath(t *testing.T) {
+	graph, line := setUpWaypointLine(t, 6)
+
+	path, err := FindOptimalWaypointPath(
+		graph, line[0], line[5], []*Node{line[4], line[1], line[3]}, nil,
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, line, path)
+
+	// Without waypoints, the direct path is returned.
+	path, err = FindOptimalWaypointPath(graph, line[0], line[5], nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, line, path)
+}
+
+func TestFindOptimalWaypointPathBackAndForth(t *testing.T) {
+	graph, line := setUpWaypointLine(t, 5)
+
+	// Start in the middle. Going to the closer waypoint first is cheaper.
+	path, err := FindOptimalWaypointPath(
+		graph, line[1], line[1], []*Node{line[4], line[0]}, nil,
+	)
+
+	assert.NoError(t, err)
+	expected := []*Node{
+		line[1], line[0], line[1], line[2], line[3], line[4], line[3], line[2], line[1],
+	}
+	assert.Equal(t, expected, path)
+}
+
+func TestFindOptimalWaypointPathUnreachable(t *testing.T) {
+	graph, line := setUpWaypointLine(t, 5)
+	// Waypoint 4 can be entered but never left.
+	line[4].RemoveConnection(line[3])
+
+	_, err := FindOptimalWaypointPath(graph, line[0], line[2], []*Node{line[4]}, nil)
+	assert.Error(t, err)
+
+	// Visiting it last works, though.
+	path, err := FindOptimalWaypointPath(graph, line[0], line[4], []*Node{line[2]}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, line, path)
+}
+
+func TestFindOptimalWaypointPathFailure(t *testing.T) {
+	graph, line := setUpWaypointLine(t, 3)
+	outside, err := NewNode("outside", 0, 0, nil)
+	assert.NoError(t, err)
+
+	tooMany := make([]*Node, MaxOptimalWaypoints+1)
+	for idx := range tooMany {
+		tooMany[idx] = line[1]
+	}
+	_, err = FindOptimalWaypointPath(graph, line[0], line[2], tooMany, nil)
+	assert.Error(t, err)
+
+	_, err = FindOptimalWaypointPath(graph, line[0], line[2], []*Node{outside}, nil)
+	assert.Error(t, err)
+}